@@ -22,6 +22,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 )
 
 func TestReadinessCheckValidate(t *testing.T) {
@@ -111,3 +112,82 @@ func TestReadinessCheckValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionDetailValidate(t *testing.T) {
+	type args struct {
+		cd *ConnectionDetail
+	}
+	type want struct {
+		output *field.Error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ValidName": {
+			reason: "A name made of alphanumeric characters, '-', '_' and '.' should be valid",
+			args: args{
+				cd: &ConnectionDetail{
+					Name:          ptr.To("db_password-1.txt"),
+					FromFieldPath: ptr.To("status.atProvider.password"),
+				},
+			},
+		},
+		"ValidFromConnectionSecretKey": {
+			reason: "A valid connection secret key should be valid if no name is set",
+			args: args{
+				cd: &ConnectionDetail{
+					FromConnectionSecretKey: ptr.To("password"),
+				},
+			},
+		},
+		"ValidNameOverridingInvalidFromConnectionSecretKey": {
+			reason: "An invalid connection secret key should be valid if a valid name is set, as the name is the resulting key",
+			args: args{
+				cd: &ConnectionDetail{
+					Name:                    ptr.To("password"),
+					FromConnectionSecretKey: ptr.To("pass word"),
+				},
+			},
+		},
+		"InvalidName": {
+			reason: "A name containing characters not allowed in secret keys should be invalid",
+			args: args{
+				cd: &ConnectionDetail{
+					Name:  ptr.To("db/password"),
+					Value: ptr.To("secret"),
+				},
+			},
+			want: want{
+				output: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "name",
+				},
+			},
+		},
+		"InvalidFromConnectionSecretKey": {
+			reason: "A connection secret key containing characters not allowed in secret keys should be invalid if no name is set",
+			args: args{
+				cd: &ConnectionDetail{
+					FromConnectionSecretKey: ptr.To("pass word"),
+				},
+			},
+			want: want{
+				output: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "fromConnectionSecretKey",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.cd.Validate()
+			if diff := cmp.Diff(tc.want.output, got, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("%s\nValidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
@@ -156,6 +157,9 @@ func (p *Patch) Validate() *field.Error {
 		if p.ToFieldPath == nil {
 			return field.Required(field.NewPath("toFieldPath"), fmt.Sprintf("toFieldPath must be set for patch type %s", p.Type))
 		}
+		if err := p.Combine.Validate(); err != nil {
+			return verrors.WrapFieldError(err, field.NewPath("combine"))
+		}
 	default:
 		// Should never happen
 		return field.Invalid(field.NewPath("type"), p.Type, "unknown patch type")
@@ -206,6 +210,49 @@ type Combine struct {
 	String *StringCombine `json:"string,omitempty"`
 }
 
+// Validate checks this Combine is valid.
+func (c *Combine) Validate() *field.Error {
+	if c.Strategy != CombineStrategyString || c.String == nil {
+		return nil
+	}
+	verbs, ok := GetFormatVerbs(c.String.Format)
+	if ok && len(verbs) != len(c.Variables) {
+		return field.Invalid(field.NewPath("string", "fmt"), c.String.Format, fmt.Sprintf("format has %d verbs, but %d variables are combined", len(verbs), len(c.Variables)))
+	}
+	return nil
+}
+
+// GetFormatVerbs returns the verbs in the supplied Go format string, in
+// order, ignoring escaped percent signs. It returns false if verbs can't be
+// mapped one to one to arguments, i.e. the format uses explicit argument
+// indexes or reads width or precision from arguments.
+func GetFormatVerbs(format string) ([]rune, bool) {
+	verbs := make([]rune, 0)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		// Skip any flag, width and precision preceding the verb.
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && (format[i] == '[' || format[i] == '*') {
+			return nil, false
+		}
+		if i >= len(format) {
+			// A trailing percent sign is printed as %!(NOVERB), but still
+			// doesn't consume any argument.
+			break
+		}
+		verbs = append(verbs, rune(format[i]))
+	}
+	return verbs, true
+}
+
 // A StringCombine combines multiple input values into a single string.
 type StringCombine struct {
 	// Format the input using a Go format string. See
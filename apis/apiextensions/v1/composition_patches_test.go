@@ -149,6 +149,107 @@ func TestPatchValidate(t *testing.T) {
 				},
 			},
 		},
+		"InvalidCombineTooFewVerbs": {
+			reason: "Combine with fewer format verbs than variables should return error",
+			args: args{
+				patch: &Patch{
+					Type: PatchTypeCombineFromComposite,
+					Combine: &Combine{
+						Variables: []CombineVariable{
+							{
+								FromFieldPath: "spec.forProvider.var1",
+							},
+							{
+								FromFieldPath: "spec.forProvider.var2",
+							},
+							{
+								FromFieldPath: "spec.forProvider.var3",
+							},
+						},
+						Strategy: CombineStrategyString,
+						String: &StringCombine{
+							Format: "%s-%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.forProvider.foo"),
+				},
+			},
+			want: want{
+				err: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "combine.string.fmt",
+				},
+			},
+		},
+		"InvalidCombineTooManyVerbs": {
+			reason: "Combine with more format verbs than variables should return error",
+			args: args{
+				patch: &Patch{
+					Type: PatchTypeCombineFromComposite,
+					Combine: &Combine{
+						Variables: []CombineVariable{
+							{
+								FromFieldPath: "spec.forProvider.var1",
+							},
+						},
+						Strategy: CombineStrategyString,
+						String: &StringCombine{
+							Format: "%s-%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.forProvider.foo"),
+				},
+			},
+			want: want{
+				err: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "combine.string.fmt",
+				},
+			},
+		},
+		"ValidCombineMatchingVerbsWithEscapedPercent": {
+			reason: "Combine with as many format verbs as variables should be valid, ignoring escaped percent signs",
+			args: args{
+				patch: &Patch{
+					Type: PatchTypeCombineFromComposite,
+					Combine: &Combine{
+						Variables: []CombineVariable{
+							{
+								FromFieldPath: "spec.forProvider.var1",
+							},
+							{
+								FromFieldPath: "spec.forProvider.var2",
+							},
+						},
+						Strategy: CombineStrategyString,
+						String: &StringCombine{
+							Format: "%s-%05.2f%%",
+						},
+					},
+					ToFieldPath: ptr.To("spec.forProvider.foo"),
+				},
+			},
+		},
+		"ValidCombineExplicitArgumentIndexes": {
+			reason: "Combine using explicit argument indexes should not be checked against the number of variables",
+			args: args{
+				patch: &Patch{
+					Type: PatchTypeCombineFromComposite,
+					Combine: &Combine{
+						Variables: []CombineVariable{
+							{
+								FromFieldPath: "spec.forProvider.var1",
+							},
+						},
+						Strategy: CombineStrategyString,
+						String: &StringCombine{
+							Format: "%[1]s-%[1]s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.forProvider.foo"),
+				},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -266,6 +266,11 @@ func (m *MatchTransform) Validate() *field.Error {
 			return verrors.WrapFieldError(err, field.NewPath("patterns").Index(i))
 		}
 	}
+	switch m.FallbackTo {
+	case MatchFallbackToTypeValue, MatchFallbackToTypeInput, "":
+	default:
+		return field.Invalid(field.NewPath("fallbackTo"), m.FallbackTo, "unknown fallbackTo type")
+	}
 	return nil
 }
 
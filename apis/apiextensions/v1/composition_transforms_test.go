@@ -240,6 +240,86 @@ func TestTransformValidate(t *testing.T) {
 				},
 			},
 		},
+		"ValidMatchTransformFallbackToInput": {
+			reason: "Match transform falling back to the input should be valid",
+			args: args{
+				transform: &Transform{
+					Type: TransformTypeMatch,
+					Match: &MatchTransform{
+						Patterns: []MatchTransformPattern{
+							{
+								Literal: ptr.To("foo"),
+							},
+						},
+						FallbackTo: MatchFallbackToTypeInput,
+					},
+				},
+			},
+		},
+		"InvalidMatchTransformUnknownFallbackTo": {
+			reason: "Match transform with an unknown fallbackTo should be invalid",
+			args: args{
+				transform: &Transform{
+					Type: TransformTypeMatch,
+					Match: &MatchTransform{
+						Patterns: []MatchTransformPattern{
+							{
+								Literal: ptr.To("foo"),
+							},
+						},
+						FallbackTo: "Nowhere",
+					},
+				},
+			},
+			want: want{
+				err: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "match.fallbackTo",
+				},
+			},
+		},
+		"ValidMatchTransformFallbackToInputWithValue": {
+			reason: "Match transform falling back to the input with a fallback value should be valid, the Composition warns about it",
+			args: args{
+				transform: &Transform{
+					Type: TransformTypeMatch,
+					Match: &MatchTransform{
+						Patterns: []MatchTransformPattern{
+							{
+								Literal: ptr.To("foo"),
+							},
+						},
+						FallbackTo:    MatchFallbackToTypeInput,
+						FallbackValue: extv1.JSON{Raw: []byte(`"bar"`)},
+					},
+				},
+			},
+		},
+		"InvalidMatchTransformUnknownPatternType": {
+			reason: "Match transform with a pattern of unknown type should be invalid",
+			args: args{
+				transform: &Transform{
+					Type: TransformTypeMatch,
+					Match: &MatchTransform{
+						Patterns: []MatchTransformPattern{
+							{
+								Literal: ptr.To("foo"),
+							},
+							{
+								Type:    "glob",
+								Literal: ptr.To("b*"),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				err: &field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "match.patterns[1].type",
+				},
+			},
+		},
 		"InvalidStringNoString": {
 			reason: "String transform with no string set should be invalid",
 			args: args{
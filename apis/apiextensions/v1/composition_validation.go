@@ -17,6 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -37,7 +42,11 @@ func (c *Composition) Validate() (warns []string, errs field.ErrorList) {
 	for _, f := range validations {
 		errs = append(errs, f()...)
 	}
-	return nil, errs
+	warns = append(warns, c.validateComposedIdentities()...)
+	warns = append(warns, c.validateBaseStatuses()...)
+	warns = append(warns, c.validateEnvironmentWriteConflicts()...)
+	warns = append(warns, c.validateMatchFallbacks()...)
+	return warns, errs
 }
 
 func (c *Composition) validateMode() (errs field.ErrorList) {
@@ -124,7 +133,11 @@ func (c *Composition) validateResources() (errs field.ErrorList) {
 				errs = append(errs, verrors.WrapFieldError(err, field.NewPath("spec", "resources").Index(i).Child("readinessChecks").Index(j)))
 			}
 		}
-		// TODO(phisco): we should validate also ConnectionDetails, but would need a major refactoring
+		for j, cd := range res.ConnectionDetails {
+			if err := cd.Validate(); err != nil {
+				errs = append(errs, verrors.WrapFieldError(err, field.NewPath("spec", "resources").Index(i).Child("connectionDetails").Index(j)))
+			}
+		}
 	}
 	return errs
 }
@@ -140,6 +153,9 @@ func (c *Composition) validateResources() (errs field.ErrorList) {
 //  3. If the composition has any functions, it must have only named resources: This is necessary for the
 //     FunctionComposer to be able to associate entries in the spec.resources array with entries in a RunFunctionRequest's observed
 //     and desired objects.
+//  4. All names are valid Kubernetes label values, i.e. at most 63 alphanumeric characters, '-', '_' or '.', starting
+//     and ending with an alphanumeric character: because names are used as keys and annotation values by other parts
+//     of the code, and end up in the field managers of composed resources.
 func (c *Composition) validateResourceNames() (errs field.ErrorList) {
 	seen := map[string]bool{}
 	for resourceIndex, res := range c.Spec.Resources {
@@ -159,6 +175,11 @@ func (c *Composition) validateResourceNames() (errs field.ErrorList) {
 			}
 			continue
 		}
+		// Check that the name is safe to use as a key or label value. Don't
+		// skip the other checks, the name is still a name.
+		if msgs := validation.IsValidLabelValue(name); len(msgs) != 0 {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "resources").Index(resourceIndex).Child("name"), name, strings.Join(msgs, ", ")))
+		}
 		// Check that the name is unique
 		if seen[name] {
 			errs = append(errs, field.Duplicate(field.NewPath("spec", "resources").Index(resourceIndex).Child("name"), name))
@@ -174,6 +195,137 @@ func (c *Composition) validateResourceNames() (errs field.ErrorList) {
 	return errs
 }
 
+// composedIdentity is the identity of a composed resource, as rendered from
+// the base of its template.
+type composedIdentity struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// validateComposedIdentities warns about resource templates that would render
+// to the same apiVersion, kind, namespace and name of a previous template, and
+// would therefore overwrite it. Only templates whose name is set statically in
+// their base, and not patched, are considered.
+func (c *Composition) validateComposedIdentities() (warns []string) {
+	seen := map[composedIdentity]int{}
+	for i, res := range c.Spec.Resources {
+		id := composedIdentity{}
+		if err := json.Unmarshal(res.Base.Raw, &id); err != nil || id.Metadata.Name == "" {
+			continue
+		}
+		if c.patchesComposedIdentity(res.Patches) {
+			continue
+		}
+		if j, ok := seen[id]; ok {
+			warns = append(warns, fmt.Sprintf("%s: composed resource %s %q has the same identity as the one rendered by %s, it will overwrite it",
+				field.NewPath("spec", "resources").Index(i).Child("base"), id.Kind, id.Metadata.Name, field.NewPath("spec", "resources").Index(j)))
+			continue
+		}
+		seen[id] = i
+	}
+	return warns
+}
+
+// validateBaseStatuses warns about resource templates whose base has a
+// non-empty status. Composed resources are rendered from their desired state
+// only, so a status in a base is usually a leftover of copying an existing
+// resource, and is never composed.
+func (c *Composition) validateBaseStatuses() (warns []string) {
+	for i, res := range c.Spec.Resources {
+		base := struct {
+			Status map[string]any `json:"status"`
+		}{}
+		if err := json.Unmarshal(res.Base.Raw, &base); err != nil || len(base.Status) == 0 {
+			continue
+		}
+		warns = append(warns, fmt.Sprintf("%s: base should only describe the desired state of the composed resource, its status is never composed", field.NewPath("spec", "resources").Index(i).Child("base", "status")))
+	}
+	return warns
+}
+
+// patchesComposedIdentity returns true if any of the supplied patches, or of
+// the patch sets they reference, may change the name or namespace of the
+// composed resource.
+func (c *Composition) patchesComposedIdentity(patches []Patch) bool {
+	for _, p := range patches {
+		switch p.GetType() {
+		case PatchTypePatchSet:
+			for _, ps := range c.Spec.PatchSets {
+				if p.PatchSetName != nil && ps.Name == *p.PatchSetName && c.patchesComposedIdentity(ps.Patches) {
+					return true
+				}
+			}
+		case PatchTypeFromCompositeFieldPath, PatchTypeCombineFromComposite, PatchTypeFromEnvironmentFieldPath, PatchTypeCombineFromEnvironment:
+			to := p.GetToFieldPath()
+			if to == "" {
+				// Patches default to the same path they're patching from.
+				to = p.GetFromFieldPath()
+			}
+			// Matches both metadata.name and metadata.namespace.
+			if to == "metadata" || strings.HasPrefix(to, "metadata.name") {
+				return true
+			}
+		case PatchTypeToCompositeFieldPath, PatchTypeCombineToComposite, PatchTypeToEnvironmentFieldPath, PatchTypeCombineToEnvironment:
+		}
+	}
+	return false
+}
+
+// validateEnvironmentWriteConflicts warns about patches writing to an
+// environment field path already written by a previous patch, of the same or
+// of another resource template. Only the last one to be applied would win.
+func (c *Composition) validateEnvironmentWriteConflicts() (warns []string) {
+	seen := map[string]*field.Path{}
+	for i, res := range c.Spec.Resources {
+		for j, p := range res.Patches {
+			path := field.NewPath("spec", "resources").Index(i).Child("patches").Index(j)
+			for _, to := range c.getEnvironmentWrites(p) {
+				if prev, ok := seen[to]; ok {
+					warns = append(warns, fmt.Sprintf("%s: writes environment field path %q, already written by %s, only the last one to be applied will win", path, to, prev))
+					continue
+				}
+				seen[to] = path
+			}
+		}
+	}
+	return warns
+}
+
+// getEnvironmentWrites returns the environment field paths the supplied patch,
+// or the patch set it references, writes to.
+func (c *Composition) getEnvironmentWrites(p Patch) []string {
+	switch p.GetType() { //nolint:exhaustive // Only these patch types write to the environment.
+	case PatchTypePatchSet:
+		var to []string
+		for _, ps := range c.Spec.PatchSets {
+			if p.PatchSetName == nil || ps.Name != *p.PatchSetName {
+				continue
+			}
+			for _, psp := range ps.Patches {
+				to = append(to, c.getEnvironmentWrites(psp)...)
+			}
+		}
+		return to
+	case PatchTypeToEnvironmentFieldPath:
+		if to := p.GetToFieldPath(); to != "" {
+			return []string{to}
+		}
+		// Patches default to the same path they're patching from.
+		if from := p.GetFromFieldPath(); from != "" {
+			return []string{from}
+		}
+	case PatchTypeCombineToEnvironment:
+		if to := p.GetToFieldPath(); to != "" {
+			return []string{to}
+		}
+	}
+	return nil
+}
+
 // validateEnvironment checks that the environment is logically valid.
 func (c *Composition) validateEnvironment() field.ErrorList {
 	if c.Spec.Environment == nil {
@@ -184,3 +336,32 @@ func (c *Composition) validateEnvironment() field.ErrorList {
 	}
 	return nil
 }
+
+// validateMatchFallbacks warns about match transforms setting a fallbackValue
+// while falling back to their input, the fallbackValue is ignored.
+func (c *Composition) validateMatchFallbacks() (warns []string) {
+	check := func(path *field.Path, transforms []Transform) {
+		for i, t := range transforms {
+			if t.Match == nil || t.Match.FallbackTo != MatchFallbackToTypeInput || len(t.Match.FallbackValue.Raw) == 0 {
+				continue
+			}
+			warns = append(warns, fmt.Sprintf("%s: fallbackValue is ignored if fallbackTo is Input", path.Index(i).Child("match", "fallbackValue")))
+		}
+	}
+	for i, ps := range c.Spec.PatchSets {
+		for j, p := range ps.Patches {
+			check(field.NewPath("spec", "patchSets").Index(i).Child("patches").Index(j).Child("transforms"), p.Transforms)
+		}
+	}
+	for i, res := range c.Spec.Resources {
+		for j, p := range res.Patches {
+			check(field.NewPath("spec", "resources").Index(i).Child("patches").Index(j).Child("transforms"), p.Transforms)
+		}
+	}
+	if c.Spec.Environment != nil {
+		for i, p := range c.Spec.Environment.Patches {
+			check(field.NewPath("spec", "environment", "patches").Index(i).Child("transforms"), p.Transforms)
+		}
+	}
+	return warns
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 )
@@ -177,6 +179,78 @@ func TestCompositionValidateResourceName(t *testing.T) {
 				},
 			},
 		},
+		"ValidNames": {
+			reason: "Names made of alphanumeric characters, '-', '_' and '.' are valid",
+			args: args{
+				spec: CompositionSpec{
+					Resources: []ComposedTemplate{
+						{Name: ptr.To("my-bucket")},
+						{Name: ptr.To("myBucket_2")},
+						{Name: ptr.To("bucket.v1")},
+					},
+				},
+			},
+		},
+		"InvalidNames": {
+			reason: "Names with characters other than alphanumeric characters, '-', '_' and '.', or too long, are invalid",
+			args: args{
+				spec: CompositionSpec{
+					Resources: []ComposedTemplate{
+						{Name: ptr.To("my bucket")},
+						{Name: ptr.To("my/bucket")},
+						{Name: ptr.To("-bucket")},
+						{Name: ptr.To(strings.Repeat("a", 64))},
+					},
+				},
+			},
+			want: want{
+				output: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].name",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[1].name",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[2].name",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[3].name",
+					},
+				},
+			},
+		},
+		"InvalidNameAndDuplicate": {
+			reason: "An invalid name should be reported along any other issue with it",
+			args: args{
+				spec: CompositionSpec{
+					Resources: []ComposedTemplate{
+						{Name: ptr.To("my bucket")},
+						{Name: ptr.To("my bucket")},
+					},
+				},
+			},
+			want: want{
+				output: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].name",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[1].name",
+					},
+					{
+						Type:  field.ErrorTypeDuplicate,
+						Field: "spec.resources[1].name",
+					},
+				},
+			},
+		},
 		"InvalidMixedNamesExpectingAnonymous": {
 			reason: "starting with anonymous resources and mixing named resources is invalid",
 			args: args{
@@ -683,6 +757,39 @@ func TestCompositionValidateResources(t *testing.T) {
 				},
 			},
 		},
+		"InvalidConnectionDetailName": {
+			reason: "resource with a connection detail resulting in an invalid secret key should be invalid",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{
+								Name: ptr.To("foo"),
+								ConnectionDetails: []ConnectionDetail{
+									{
+										Name:          ptr.To("password"),
+										FromFieldPath: ptr.To("status.atProvider.password"),
+									},
+									{
+										Name:          ptr.To("user:name"),
+										FromFieldPath: ptr.To("status.atProvider.username"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				output: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].connectionDetails[1].name",
+						BadValue: "user:name",
+					},
+				},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -694,6 +801,373 @@ func TestCompositionValidateResources(t *testing.T) {
 	}
 }
 
+func TestCompositionValidateComposedIdentities(t *testing.T) {
+	base := func(name string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"` + name + `"}}`)}
+	}
+	type args struct {
+		comp *Composition
+	}
+	type want struct {
+		warns []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DistinctNames": {
+			reason: "Resources with distinct static names should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("bucket-a")},
+							{Name: ptr.To("b"), Base: base("bucket-b")},
+						},
+					},
+				},
+			},
+		},
+		"NoStaticNames": {
+			reason: "Resources without a static name should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("")},
+							{Name: ptr.To("b"), Base: base("")},
+						},
+					},
+				},
+			},
+		},
+		"CollidingNames": {
+			reason: "Resources rendering to the same apiVersion, kind and name should be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("bucket")},
+							{Name: ptr.To("b"), Base: base("other")},
+							{Name: ptr.To("c"), Base: base("bucket")},
+						},
+					},
+				},
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[2].base: composed resource Bucket "bucket" has the same identity as the one rendered by spec.resources[0], it will overwrite it`,
+				},
+			},
+		},
+		"CollidingNamesPatched": {
+			reason: "Resources whose name is patched, directly or through a patch set, should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						PatchSets: []PatchSet{
+							{
+								Name: "name",
+								Patches: []Patch{{
+									Type:          PatchTypeFromCompositeFieldPath,
+									FromFieldPath: ptr.To("spec.name"),
+									ToFieldPath:   ptr.To("metadata.name"),
+								}},
+							},
+						},
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("bucket")},
+							{
+								Name: ptr.To("b"),
+								Base: base("bucket"),
+								Patches: []Patch{{
+									Type:          PatchTypeFromCompositeFieldPath,
+									FromFieldPath: ptr.To("metadata.namespace"),
+								}},
+							},
+							{
+								Name: ptr.To("c"),
+								Base: base("bucket"),
+								Patches: []Patch{{
+									Type:         PatchTypePatchSet,
+									PatchSetName: ptr.To("name"),
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.comp.validateComposedIdentities()
+			if diff := cmp.Diff(tc.want.warns, got); diff != "" {
+				t.Errorf("%s\nvalidateComposedIdentities(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionValidateBaseStatuses(t *testing.T) {
+	base := func(status string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Bucket","spec":{"region":"eu"}` + status + `}`)}
+	}
+	type args struct {
+		comp *Composition
+	}
+	type want struct {
+		warns []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoStatus": {
+			reason: "Bases without a status should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("")},
+						},
+					},
+				},
+			},
+		},
+		"EmptyStatus": {
+			reason: "Bases with an empty status should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base(`,"status":{}`)},
+						},
+					},
+				},
+			},
+		},
+		"Status": {
+			reason: "Bases with a non-empty status should be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Base: base("")},
+							{Name: ptr.To("b"), Base: base(`,"status":{"atProvider":{"arn":"arn:aws:s3:::bucket"}}`)},
+						},
+					},
+				},
+			},
+			want: want{
+				warns: []string{
+					"spec.resources[1].base.status: base should only describe the desired state of the composed resource, its status is never composed",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.comp.validateBaseStatuses()
+			if diff := cmp.Diff(tc.want.warns, got); diff != "" {
+				t.Errorf("%s\nvalidateBaseStatuses(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionValidateEnvironmentWriteConflicts(t *testing.T) {
+	toEnv := func(from, to string) Patch {
+		return Patch{
+			Type:          PatchTypeToEnvironmentFieldPath,
+			FromFieldPath: ptr.To(from),
+			ToFieldPath:   ptr.To(to),
+		}
+	}
+	type args struct {
+		comp *Composition
+	}
+	type want struct {
+		warns []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DistinctPaths": {
+			reason: "Patches writing distinct environment field paths should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{toEnv("status.id", "a.id")}},
+							{Name: ptr.To("b"), Patches: []Patch{
+								toEnv("status.id", "b.id"),
+								{
+									Type:          PatchTypeFromEnvironmentFieldPath,
+									FromFieldPath: ptr.To("a.id"),
+									ToFieldPath:   ptr.To("spec.aID"),
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"ConflictingPaths": {
+			reason: "Patches writing the same environment field path should be flagged, whatever resource they belong to",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{toEnv("status.id", "id")}},
+							{Name: ptr.To("b"), Patches: []Patch{
+								toEnv("status.id", "id"),
+								{
+									Type: PatchTypeCombineToEnvironment,
+									Combine: &Combine{
+										Variables: []CombineVariable{{FromFieldPath: "status.id"}},
+										Strategy:  CombineStrategyString,
+										String:    &StringCombine{Format: "%s"},
+									},
+									ToFieldPath: ptr.To("id"),
+								},
+							}},
+						},
+					},
+				},
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[1].patches[0]: writes environment field path "id", already written by spec.resources[0].patches[0], only the last one to be applied will win`,
+					`spec.resources[1].patches[1]: writes environment field path "id", already written by spec.resources[0].patches[0], only the last one to be applied will win`,
+				},
+			},
+		},
+		"ConflictingPathsFromPatchSet": {
+			reason: "A patch set writing an environment field path used by multiple resources should be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						PatchSets: []PatchSet{
+							{Name: "env", Patches: []Patch{toEnv("status.id", "id")}},
+						},
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{{Type: PatchTypePatchSet, PatchSetName: ptr.To("env")}}},
+							{Name: ptr.To("b"), Patches: []Patch{{Type: PatchTypePatchSet, PatchSetName: ptr.To("env")}}},
+						},
+					},
+				},
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[1].patches[0]: writes environment field path "id", already written by spec.resources[0].patches[0], only the last one to be applied will win`,
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.comp.validateEnvironmentWriteConflicts()
+			if diff := cmp.Diff(tc.want.warns, got); diff != "" {
+				t.Errorf("%s\nvalidateEnvironmentWriteConflicts(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionValidateMatchFallbacks(t *testing.T) {
+	match := func(to MatchFallbackTo, value string) Transform {
+		m := &MatchTransform{
+			Patterns:   []MatchTransformPattern{{Type: MatchTransformPatternTypeLiteral, Literal: ptr.To("foo")}},
+			FallbackTo: to,
+		}
+		if value != "" {
+			m.FallbackValue = extv1.JSON{Raw: []byte(value)}
+		}
+		return Transform{Type: TransformTypeMatch, Match: m}
+	}
+	type args struct {
+		comp *Composition
+	}
+	type want struct {
+		warns []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FallbackToValue": {
+			reason: "Match transforms falling back to a value should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{{Transforms: []Transform{match(MatchFallbackToTypeValue, `"bar"`)}}}},
+						},
+					},
+				},
+			},
+		},
+		"FallbackToInputWithoutValue": {
+			reason: "Match transforms falling back to their input without a fallback value should not be flagged",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{{Transforms: []Transform{match(MatchFallbackToTypeInput, "")}}}},
+						},
+					},
+				},
+			},
+		},
+		"FallbackToInputWithValue": {
+			reason: "Match transforms falling back to their input with a fallback value should be flagged, wherever they are",
+			args: args{
+				comp: &Composition{
+					Spec: CompositionSpec{
+						PatchSets: []PatchSet{
+							{Name: "ps", Patches: []Patch{{Transforms: []Transform{match(MatchFallbackToTypeInput, `"bar"`)}}}},
+						},
+						Resources: []ComposedTemplate{
+							{Name: ptr.To("a"), Patches: []Patch{{Transforms: []Transform{
+								match(MatchFallbackToTypeValue, `"bar"`),
+								match(MatchFallbackToTypeInput, `"bar"`),
+							}}}},
+						},
+						Environment: &EnvironmentConfiguration{
+							Patches: []EnvironmentPatch{{Transforms: []Transform{match(MatchFallbackToTypeInput, `"bar"`)}}},
+						},
+					},
+				},
+			},
+			want: want{
+				warns: []string{
+					"spec.patchSets[0].patches[0].transforms[0].match.fallbackValue: fallbackValue is ignored if fallbackTo is Input",
+					"spec.resources[0].patches[0].transforms[1].match.fallbackValue: fallbackValue is ignored if fallbackTo is Input",
+					"spec.environment.patches[0].transforms[0].match.fallbackValue: fallbackValue is ignored if fallbackTo is Input",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.comp.validateMatchFallbacks()
+			if diff := cmp.Diff(tc.want.warns, got); diff != "" {
+				t.Errorf("%s\nvalidateMatchFallbacks(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestCompositionValidateEnvironment(t *testing.T) {
 	type args struct {
 		comp *Composition
@@ -19,9 +19,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -284,6 +287,26 @@ type ConnectionDetail struct {
 	Value *string `json:"value,omitempty"`
 }
 
+// Validate checks if the connection detail is logically valid. The key it
+// results in, i.e. its name or, if not set, the connection secret key it's
+// fetched from, must be a valid secret key.
+func (cd *ConnectionDetail) Validate() *field.Error {
+	key, path := "", field.NewPath("name")
+	switch {
+	case cd.Name != nil && *cd.Name != "":
+		key = *cd.Name
+	case cd.FromConnectionSecretKey != nil:
+		key, path = *cd.FromConnectionSecretKey, field.NewPath("fromConnectionSecretKey")
+	}
+	if key == "" {
+		return nil
+	}
+	if msgs := validation.IsConfigMapKey(key); len(msgs) != 0 {
+		return field.Invalid(path, key, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
 // A PipelineStep in a Composition Function pipeline.
 type PipelineStep struct {
 	// Step name. Must be unique within its Pipeline.
@@ -21,6 +21,8 @@ package beta
 
 import (
 	"github.com/crossplane/crossplane/cmd/crank/beta/convert"
+	"github.com/crossplane/crossplane/cmd/crank/beta/explain"
+	"github.com/crossplane/crossplane/cmd/crank/beta/lint"
 	"github.com/crossplane/crossplane/cmd/crank/beta/render"
 	"github.com/crossplane/crossplane/cmd/crank/beta/top"
 	"github.com/crossplane/crossplane/cmd/crank/beta/trace"
@@ -32,12 +34,15 @@ import (
 type Cmd struct {
 	// Subcommands and flags will appear in the CLI help output in the same
 	// order they're specified here. Keep them in alphabetical order.
-	Convert  convert.Cmd  `cmd:"" help:"Convert a Crossplane resource to a newer version or kind."`
-	Render   render.Cmd   `cmd:"" help:"Render a composite resource (XR)."`
-	Top      top.Cmd      `cmd:"" help:"Display resource (CPU/memory) usage by Crossplane related pods."`
-	Trace    trace.Cmd    `cmd:"" help:"Trace a Crossplane resource to get a detailed output of its relationships, helpful for troubleshooting."`
-	XPKG     xpkg.Cmd     `cmd:"" help:"Manage Crossplane packages."`
-	Validate validate.Cmd `cmd:"" help:"Validate Crossplane resources."`
+	Convert             convert.Cmd         `cmd:"" help:"Convert a Crossplane resource to a newer version or kind."`
+	Explain             explain.Cmd         `cmd:"" help:"Explain where the fields of the resources composed for a composite resource (XR) get their values from."`
+	Lint                lint.Cmd            `cmd:"" help:"Report the use of deprecated Composition features, like patches and transforms."`
+	Render              render.Cmd          `cmd:"" help:"Render a composite resource (XR)."`
+	Top                 top.Cmd             `cmd:"" help:"Display resource (CPU/memory) usage by Crossplane related pods."`
+	Trace               trace.Cmd           `cmd:"" help:"Trace a Crossplane resource to get a detailed output of its relationships, helpful for troubleshooting."`
+	XPKG                xpkg.Cmd            `cmd:"" help:"Manage Crossplane packages."`
+	Validate            validate.Cmd        `cmd:"" help:"Validate Crossplane resources."`
+	ValidateComposition validate.ClusterCmd `cmd:"" help:"Validate a Composition installed in a cluster against the installed schemas."`
 }
 
 // Help output for crossplane beta.
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package explain implements explaining where the fields of composed resources
+// get their values from.
+package explain
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alecthomas/kong"
+	"github.com/spf13/afero"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/cmd/crank/beta/render"
+	"github.com/crossplane/crossplane/cmd/crank/internal/normalize"
+)
+
+// Cmd arguments and flags for explain subcommand.
+type Cmd struct {
+	// Arguments.
+	CompositeResource string `arg:"" help:"A YAML file specifying the composite resource (XR) to explain."                             type:"existingfile"`
+	Composition       string `arg:"" help:"A YAML file specifying the Composition to use to render the XR. Must be mode: Resources." type:"existingfile"`
+
+	// Flags. Keep them in alphabetical order.
+	Resource string `help:"Only explain the composed resource template with this name." placeholder:"NAME" short:"r"`
+
+	fs afero.Fs
+}
+
+// Help prints out the help for the explain command.
+func (c *Cmd) Help() string {
+	return `
+This command shows, field by field, where the composed resources Crossplane
+would create for an XR get their values from. Each field is reported as set by
+the composed template's base, by one of its patches (identified by index), or
+by Crossplane itself (Default). It doesn't talk to Crossplane. It only supports
+Compositions in Resources mode, and ignores patches from the environment.
+
+Examples:
+
+  # Explain all composed resources.
+  crossplane beta explain xr.yaml composition.yaml
+
+  # Explain only the composed resource template named "bucket".
+  crossplane beta explain xr.yaml composition.yaml --resource=bucket
+`
+}
+
+// AfterApply implements kong.AfterApply.
+func (c *Cmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+// Run explain.
+func (c *Cmd) Run(k *kong.Context) error {
+	xr, err := render.LoadCompositeResource(c.fs, c.CompositeResource)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load composite resource from %q", c.CompositeResource)
+	}
+
+	comp, err := render.LoadComposition(c.fs, c.Composition)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load Composition from %q", c.Composition)
+	}
+
+	if _, errs := comp.Validate(); len(errs) > 0 {
+		return errors.Wrapf(errs.ToAggregate(), "invalid Composition %q", comp.GetName())
+	}
+
+	es, err := Explain(xr, comp)
+	if err != nil {
+		return errors.Wrap(err, "cannot explain composite resource")
+	}
+
+	return errors.Wrap(c.print(k.Stdout, es), "cannot print explanation")
+}
+
+// print the supplied explanations, omitting the volatile fields render would
+// strip from the rendered resources, e.g. a creationTimestamp copied into a
+// base.
+func (c *Cmd) print(w io.Writer, es []ResourceExplanation) error {
+	tw := printers.GetNewTabWriter(w)
+	if _, err := fmt.Fprintln(tw, "RESOURCE\tFIELD\tSOURCE\tVALUE"); err != nil {
+		return err
+	}
+	for _, e := range es {
+		if c.Resource != "" && e.Name != c.Resource {
+			continue
+		}
+		kept := leaves(normalize.Unstructured(&e.Resource.Unstructured).Object)
+		for _, f := range e.Fields {
+			if _, ok := kept[f.FieldPath]; !ok {
+				continue
+			}
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%v\n", e.Name, f.FieldPath, f, f.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Flush()
+}
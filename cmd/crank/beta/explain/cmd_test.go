@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+)
+
+func TestCmdPrint(t *testing.T) {
+	es := []ResourceExplanation{{
+		Name: "bucket",
+		Resource: &composed.Unstructured{Unstructured: unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "s3.example.org/v1",
+			"kind":       "Bucket",
+			"metadata": map[string]any{
+				"creationTimestamp": nil,
+				"generateName":      "test-explain-",
+			},
+			"spec": map[string]any{"region": "us-east-1"},
+		}}},
+		Fields: []FieldProvenance{
+			{FieldPath: "apiVersion", Source: SourceBase, Value: "s3.example.org/v1"},
+			{FieldPath: "kind", Source: SourceBase, Value: "Bucket"},
+			{FieldPath: "metadata.creationTimestamp", Source: SourceBase, Value: nil},
+			{FieldPath: "metadata.generateName", Source: SourceDefault, Value: "test-explain-"},
+			{FieldPath: "spec.region", Source: SourceBase, Value: "us-east-1"},
+		},
+	}}
+
+	b := &bytes.Buffer{}
+	if err := (&Cmd{}).print(b, es); err != nil {
+		t.Fatalf("print(...): %v", err)
+	}
+
+	// Only check the field column, the others are padded by the tab writer.
+	got := make([]string, 0)
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n")[1:] {
+		got = append(got, strings.Fields(l)[1])
+	}
+	want := []string{"apiVersion", "kind", "metadata.generateName", "spec.region"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("print(...): should omit volatile fields: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	ucomposite "github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/cmd/crank/beta/render"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+)
+
+// A Source is where the value of a composed resource field comes from.
+type Source string
+
+// Sources of composed resource field values.
+const (
+	// SourceBase means the value was set by the composed template's base.
+	SourceBase Source = "Base"
+
+	// SourcePatch means the value was set by one of the composed template's
+	// patches.
+	SourcePatch Source = "Patch"
+
+	// SourceDefault means the value was set by Crossplane when rendering the
+	// composed resource, e.g. its owner references and standard labels.
+	SourceDefault Source = "Default"
+)
+
+// A FieldProvenance describes where the value of a composed resource field
+// comes from.
+type FieldProvenance struct {
+	// FieldPath of the field within the composed resource.
+	FieldPath string
+
+	// Source of the field's value.
+	Source Source
+
+	// PatchIndex is the index of the patch that last set the field's value.
+	// It's only meaningful when Source is SourcePatch.
+	PatchIndex int
+
+	// Value of the field.
+	Value any
+}
+
+// String returns a human readable representation of the source.
+func (p FieldProvenance) String() string {
+	if p.Source == SourcePatch {
+		return fmt.Sprintf("%s[%d]", p.Source, p.PatchIndex)
+	}
+	return string(p.Source)
+}
+
+// A ResourceExplanation describes where the value of each field of a composed
+// resource comes from.
+type ResourceExplanation struct {
+	// Name of the composed template that produced the resource.
+	Name string
+
+	// Resource as rendered.
+	Resource *composed.Unstructured
+
+	// Fields of the resource, sorted by field path.
+	Fields []FieldProvenance
+}
+
+// Explain renders the composed resources of the supplied Composition for the
+// supplied XR, recording where each composed field gets its value from. Only
+// Compositions in Resources mode are supported. Patches from the environment
+// and to the XR are ignored, as they don't (only) depend on the XR.
+func Explain(xr *ucomposite.Unstructured, comp *v1.Composition) ([]ResourceExplanation, error) {
+	if m := comp.Spec.Mode; m != nil && *m != v1.CompositionModeResources {
+		return nil, errors.Errorf("explain only supports Compositions using spec.mode: Resources: Composition %q uses %q", comp.GetName(), *m)
+	}
+
+	cts, err := composite.ComposedTemplates(comp.Spec.PatchSets, comp.Spec.Resources)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot associate composed resource templates with patch sets")
+	}
+
+	out := make([]ResourceExplanation, 0, len(cts))
+	for i, t := range cts {
+		name := fmt.Sprintf("resource-%d", i)
+		if t.Name != nil {
+			name = *t.Name
+		}
+		e, err := explainTemplate(xr, t, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot explain composed resource template %q", name)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func explainTemplate(xr *ucomposite.Unstructured, t v1.ComposedTemplate, name string) (ResourceExplanation, error) {
+	cd := composed.New()
+	if err := composite.RenderFromJSON(cd, t.Base.Raw); err != nil {
+		return ResourceExplanation{}, errors.Wrap(err, "cannot render base")
+	}
+
+	sources := map[string]FieldProvenance{}
+	record := func(before map[string]any, src Source, idx int) {
+		for fp, v := range leaves(cd.Object) {
+			if bv, ok := before[fp]; ok && reflect.DeepEqual(bv, v) {
+				continue
+			}
+			sources[fp] = FieldProvenance{FieldPath: fp, Source: src, PatchIndex: idx, Value: v}
+		}
+	}
+
+	record(nil, SourceBase, 0)
+
+	for i, p := range t.Patches {
+		before := leaves(cd.Object)
+		if err := composite.Apply(p, xr, cd, v1.PatchTypeFromCompositeFieldPath, v1.PatchTypeCombineFromComposite); err != nil {
+			return ResourceExplanation{}, errors.Wrapf(err, "cannot apply the %q patch at index %d", p.Type, i)
+		}
+		record(before, SourcePatch, i)
+	}
+
+	before := leaves(cd.Object)
+	if err := render.SetComposedResourceMetadata(cd, xr, name); err != nil {
+		return ResourceExplanation{}, errors.Wrap(err, "cannot render composed resource metadata")
+	}
+	record(before, SourceDefault, 0)
+
+	e := ResourceExplanation{Name: name, Resource: cd, Fields: make([]FieldProvenance, 0, len(sources))}
+	for _, p := range sources {
+		e.Fields = append(e.Fields, p)
+	}
+	sort.Slice(e.Fields, func(i, j int) bool { return e.Fields[i].FieldPath < e.Fields[j].FieldPath })
+	return e, nil
+}
+
+// leaves returns all leaf values of the supplied object, keyed by field path.
+func leaves(o map[string]any) map[string]any {
+	out := map[string]any{}
+	flatten(out, "", o)
+	return out
+}
+
+func flatten(out map[string]any, prefix string, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			out[prefix] = t
+			return
+		}
+		for k, v := range t {
+			flatten(out, join(prefix, k), v)
+		}
+	case []any:
+		if len(t) == 0 {
+			out[prefix] = t
+			return
+		}
+		for i, v := range t {
+			flatten(out, fmt.Sprintf("%s[%d]", prefix, i), v)
+		}
+	default:
+		out[prefix] = t
+	}
+}
+
+// join appends the supplied key to the supplied field path, using bracket
+// notation for keys that can't be expressed as a plain field path segment.
+func join(prefix, key string) string {
+	if strings.ContainsAny(key, "./[]") {
+		return fmt.Sprintf("%s[%s]", prefix, key)
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestExplain(t *testing.T) {
+	pipeline := v1.CompositionModePipeline
+
+	xr := &composite.Unstructured{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "nop.example.org/v1alpha1",
+		"kind":       "XNopResource",
+		"metadata":   map[string]any{"name": "test-explain"},
+		"spec":       map[string]any{"coolField": "I'm cool!"},
+	}}}
+
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		fields map[string]string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"PipelineMode": {
+			reason: "We should return an error if the Composition doesn't use Resources mode.",
+			args: args{
+				comp: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec:       v1.CompositionSpec{Mode: &pipeline},
+				},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"BaseAndPatchedFields": {
+			reason: "We should report fields set by the base, by patches, and by Crossplane.",
+			args: args{
+				comp: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec: v1.CompositionSpec{
+						Resources: []v1.ComposedTemplate{{
+							Name: ptr.To("bucket"),
+							Base: runtime.RawExtension{Raw: MustJSON(map[string]any{
+								"apiVersion": "s3.example.org/v1",
+								"kind":       "Bucket",
+								"spec": map[string]any{
+									"region":   "us-east-1",
+									"coolness": "lukewarm",
+								},
+							})},
+							Patches: []v1.Patch{
+								{
+									Type:          v1.PatchTypeFromCompositeFieldPath,
+									FromFieldPath: ptr.To("spec.coolField"),
+									ToFieldPath:   ptr.To("spec.coolness"),
+								},
+								{
+									Type:          v1.PatchTypeFromCompositeFieldPath,
+									FromFieldPath: ptr.To("metadata.name"),
+									ToFieldPath:   ptr.To("spec.bucketName"),
+								},
+							},
+						}},
+					},
+				},
+			},
+			want: want{
+				fields: map[string]string{
+					"apiVersion":      "Base",
+					"kind":            "Base",
+					"spec.region":     "Base",
+					"spec.coolness":   "Patch[0]",
+					"spec.bucketName": "Patch[1]",
+					"metadata.annotations[crossplane.io/composition-resource-name]": "Default",
+					"metadata.generateName":                          "Default",
+					"metadata.labels[crossplane.io/composite]":       "Default",
+					"metadata.ownerReferences[0].apiVersion":         "Default",
+					"metadata.ownerReferences[0].controller":         "Default",
+					"metadata.ownerReferences[0].kind":               "Default",
+					"metadata.ownerReferences[0].name":               "Default",
+					"metadata.ownerReferences[0].uid":                "Default",
+					"metadata.ownerReferences[0].blockOwnerDeletion": "Default",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			es, err := Explain(xr, tc.args.comp)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nExplain(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			var got map[string]string
+			for _, e := range es {
+				got = map[string]string{}
+				for _, f := range e.Fields {
+					got[f.FieldPath] = f.String()
+				}
+			}
+			if diff := cmp.Diff(tc.want.fields, got); diff != "" {
+				t.Errorf("%s\nExplain(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func MustJSON(o any) []byte {
+	j, err := json.Marshal(o)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint implements reporting the use of deprecated Composition
+// features.
+package lint
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alecthomas/kong"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/cmd/crank/beta/render"
+)
+
+// Cmd arguments and flags for lint subcommand.
+type Cmd struct {
+	// Arguments.
+	Composition string `arg:"" help:"A YAML file specifying the Composition to lint." type:"existingfile"`
+
+	fs afero.Fs
+}
+
+// Help prints out the help for the lint command.
+func (c *Cmd) Help() string {
+	return `
+This command reports, as warnings, where a Composition uses the classic patches
+and transforms superseded by Composition Functions, followed by how many of
+each it uses. Patches are reported where they're defined, so patches from a
+patch set are reported once, no matter how many resources use the patch set.
+It doesn't talk to Crossplane.
+
+Examples:
+
+  # Lint a Composition.
+  crossplane beta lint composition.yaml
+
+  # Convert the linted Composition to use a Function pipeline.
+  crossplane beta convert pipeline-composition composition.yaml -o pipeline-composition.yaml
+`
+}
+
+// AfterApply implements kong.AfterApply.
+func (c *Cmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+// Run lint.
+func (c *Cmd) Run(k *kong.Context) error {
+	comp, err := render.LoadComposition(c.fs, c.Composition)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load Composition from %q", c.Composition)
+	}
+
+	return errors.Wrap(Print(k.Stdout, comp.GetName(), Lint(comp)), "cannot print findings")
+}
+
+// Print writes the supplied findings for the named Composition to w, one
+// warning per finding followed by a summary.
+func Print(w io.Writer, name string, fs []Finding) error {
+	for _, f := range fs {
+		if _, err := fmt.Fprintf(w, "[!] %s: uses a deprecated %s\n", f.Path, f.Feature); err != nil {
+			return err
+		}
+	}
+	if len(fs) == 0 {
+		_, err := fmt.Fprintf(w, "[✓] composition %q doesn't use deprecated features\n", name)
+		return err
+	}
+	c := Count(fs)
+	_, err := fmt.Fprintf(w, "Composition %q uses %d patches, %d environment patches and %d transforms, consider using Composition Functions instead\n", name, c[FeaturePatch], c[FeatureEnvironmentPatch], c[FeatureTransform])
+	return err
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// A Feature of Compositions superseded by Composition Functions.
+type Feature string
+
+// Deprecated Composition features.
+const (
+	FeaturePatch            Feature = "patch"
+	FeatureEnvironmentPatch Feature = "environment patch"
+	FeatureTransform        Feature = "transform"
+)
+
+// A Finding is a use of a deprecated Composition feature.
+type Finding struct {
+	// Feature used.
+	Feature Feature
+
+	// Path where the Feature is used, e.g. spec.resources[0].patches[1].
+	Path *field.Path
+}
+
+// Lint returns the uses of deprecated features by the supplied Composition,
+// in the order they appear in its spec.
+func Lint(comp *v1.Composition) []Finding {
+	var fs []Finding
+
+	for i, ps := range comp.Spec.PatchSets {
+		for j, p := range ps.Patches {
+			fs = append(fs, lintPatch(FeaturePatch, p.Transforms, field.NewPath("spec", "patchSets").Index(i).Child("patches").Index(j))...)
+		}
+	}
+
+	if comp.Spec.Environment != nil {
+		for i, p := range comp.Spec.Environment.Patches {
+			fs = append(fs, lintPatch(FeatureEnvironmentPatch, p.Transforms, field.NewPath("spec", "environment", "patches").Index(i))...)
+		}
+	}
+
+	for i, r := range comp.Spec.Resources {
+		for j, p := range r.Patches {
+			// Patches referencing a patch set are reported there.
+			if p.GetType() == v1.PatchTypePatchSet {
+				continue
+			}
+			fs = append(fs, lintPatch(FeaturePatch, p.Transforms, field.NewPath("spec", "resources").Index(i).Child("patches").Index(j))...)
+		}
+	}
+
+	return fs
+}
+
+// lintPatch returns a finding for the supplied patch, and one for each of its
+// transforms.
+func lintPatch(f Feature, ts []v1.Transform, path *field.Path) []Finding {
+	fs := make([]Finding, 0, len(ts)+1)
+	fs = append(fs, Finding{Feature: f, Path: path})
+	for i := range ts {
+		fs = append(fs, Finding{Feature: FeatureTransform, Path: path.Child("transforms").Index(i)})
+	}
+	return fs
+}
+
+// Count returns the number of findings for each feature.
+func Count(fs []Finding) map[Feature]int {
+	c := make(map[Feature]int)
+	for _, f := range fs {
+		c[f.Feature]++
+	}
+	return c
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestLint(t *testing.T) {
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		fs []Finding
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Pipeline": {
+			reason: "A Composition using a Function pipeline should have no findings.",
+			args: args{
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						Mode: ptr.To(v1.CompositionModePipeline),
+						Pipeline: []v1.PipelineStep{
+							{Step: "patch-and-transform", FunctionRef: v1.FunctionReference{Name: "function-patch-and-transform"}},
+						},
+					},
+				},
+			},
+		},
+		"PatchesAndTransforms": {
+			reason: "Each patch and transform should be reported, patch sets where they're defined rather than where they're used.",
+			args: args{
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						PatchSets: []v1.PatchSet{
+							{
+								Name: "common",
+								Patches: []v1.Patch{
+									{FromFieldPath: ptr.To("spec.region")},
+								},
+							},
+						},
+						Environment: &v1.EnvironmentConfiguration{
+							Patches: []v1.EnvironmentPatch{
+								{
+									Type:          v1.PatchTypeFromCompositeFieldPath,
+									FromFieldPath: ptr.To("spec.env"),
+									Transforms: []v1.Transform{
+										{Type: v1.TransformTypeString},
+									},
+								},
+							},
+						},
+						Resources: []v1.ComposedTemplate{
+							{
+								Name: ptr.To("bucket"),
+								Patches: []v1.Patch{
+									{Type: v1.PatchTypePatchSet, PatchSetName: ptr.To("common")},
+									{
+										FromFieldPath: ptr.To("spec.size"),
+										Transforms: []v1.Transform{
+											{Type: v1.TransformTypeMap},
+											{Type: v1.TransformTypeString},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				fs: []Finding{
+					{Feature: FeaturePatch, Path: field.NewPath("spec", "patchSets").Index(0).Child("patches").Index(0)},
+					{Feature: FeatureEnvironmentPatch, Path: field.NewPath("spec", "environment", "patches").Index(0)},
+					{Feature: FeatureTransform, Path: field.NewPath("spec", "environment", "patches").Index(0).Child("transforms").Index(0)},
+					{Feature: FeaturePatch, Path: field.NewPath("spec", "resources").Index(0).Child("patches").Index(1)},
+					{Feature: FeatureTransform, Path: field.NewPath("spec", "resources").Index(0).Child("patches").Index(1).Child("transforms").Index(0)},
+					{Feature: FeatureTransform, Path: field.NewPath("spec", "resources").Index(0).Child("patches").Index(1).Child("transforms").Index(1)},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Lint(tc.args.comp)
+			if diff := cmp.Diff(tc.want.fs, got, cmpopts.EquateEmpty(), cmp.Transformer("Path", func(p *field.Path) string { return p.String() })); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		output string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoFindings": {
+			reason: "A Composition not using deprecated features should be reported as such.",
+			args: args{
+				comp: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+				},
+			},
+			want: want{
+				output: "[✓] composition \"pipeline\" doesn't use deprecated features\n",
+			},
+		},
+		"Findings": {
+			reason: "Each finding should be reported as a warning, followed by a summary.",
+			args: args{
+				comp: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "classic"},
+					Spec: v1.CompositionSpec{
+						Resources: []v1.ComposedTemplate{
+							{
+								Patches: []v1.Patch{
+									{
+										FromFieldPath: ptr.To("spec.size"),
+										Transforms: []v1.Transform{
+											{Type: v1.TransformTypeMap},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				output: `[!] spec.resources[0].patches[0]: uses a deprecated patch
+[!] spec.resources[0].patches[0].transforms[0]: uses a deprecated transform
+Composition "classic" uses 1 patches, 0 environment patches and 1 transforms, consider using Composition Functions instead
+`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := Print(b, tc.args.comp.GetName(), Lint(tc.args.comp)); err != nil {
+				t.Fatalf("Print(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.output, b.String()); diff != "" {
+				t.Errorf("\n%s\nPrint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
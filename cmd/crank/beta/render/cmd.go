@@ -20,7 +20,7 @@ package render
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -32,16 +32,18 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/cmd/crank/internal/normalize"
 )
 
 // Cmd arguments and flags for render subcommand.
 type Cmd struct {
 	// Arguments.
-	CompositeResource string `arg:"" help:"A YAML file specifying the composite resource (XR) to render."                                        type:"existingfile"`
-	Composition       string `arg:"" help:"A YAML file specifying the Composition to use to render the XR. Must be mode: Pipeline."              type:"existingfile"`
-	Functions         string `arg:"" help:"A YAML file or directory of YAML files specifying the Composition Functions to use to render the XR." type:"path"`
+	CompositeResource string `arg:"" help:"A YAML file or directory of YAML files specifying the composite resources (XRs) to render."                       type:"path"`
+	Composition       string `arg:"" help:"A YAML file specifying the Composition, or CompositionRevision, to use to render the XR. Must be mode: Pipeline." type:"existingfile"`
+	Functions         string `arg:"" help:"A YAML file or directory of YAML files specifying the Composition Functions to use to render the XR."             type:"path"`
 
 	// Flags. Keep them in alphabetical order.
 	ContextFiles           map[string]string `help:"Comma-separated context key-value pairs to pass to the Function pipeline. Values must be files containing JSON."                           mapsep:""`
@@ -51,6 +53,7 @@ type Cmd struct {
 	ObservedResources      string            `help:"A YAML file or directory of YAML files specifying the observed state of composed resources."                                               placeholder:"PATH" short:"o" type:"path"`
 	ExtraResources         string            `help:"A YAML file or directory of YAML files specifying extra resources to pass to the Function pipeline."                                       placeholder:"PATH" short:"e" type:"path"`
 	IncludeContext         bool              `help:"Include the context in the rendered output as a resource of kind: Context."                                                                short:"c"`
+	PruneNulls             bool              `help:"Remove fields set to null, e.g. by patches, from the rendered resources, as server-side apply would."`
 
 	Timeout time.Duration `default:"1m" help:"How long to run before timing out."`
 
@@ -64,7 +67,9 @@ This command shows you what composed resources Crossplane would create by
 printing them to stdout. It also prints any changes that would be made to the
 status of the XR. It doesn't talk to Crossplane. Instead it runs the Composition
 Function pipeline specified by the Composition locally, and uses that to render
-the XR. It only supports Compositions in Pipeline mode.
+the XR. It only supports Compositions in Pipeline mode. A CompositionRevision
+can be passed instead of a Composition, to render the XR as it was rendered
+using that revision.
 
 Composition Functions are pulled and run using Docker by default. You can add
 the following annotations to each Function to change how they're run:
@@ -98,6 +103,19 @@ Examples:
   # Simulate creating a new XR.
   crossplane beta render xr.yaml composition.yaml functions.yaml
 
+  # Simulate creating many new XRs using the same Composition. The XRs are
+  # read from a multi-document YAML file, or a directory of YAML files.
+  crossplane beta render xrs.yaml composition.yaml functions.yaml
+
+  # Simulate updating many XRs that already exist. Each XR only observes the
+  # composed resources labelled crossplane.io/composite: <XR name>.
+  crossplane beta render xrs.yaml composition.yaml functions.yaml \
+    --observed-resources=existing-observed-resources.yaml
+
+  # Simulate creating a new XR using a specific revision of a Composition.
+  kubectl get compositionrevision example-a1b2c3d -o yaml > revision.yaml
+  crossplane beta render xr.yaml revision.yaml functions.yaml
+
   # Simulate updating an XR that already exists.
   crossplane beta render xr.yaml composition.yaml functions.yaml \
     --observed-resources=existing-observed-resources.yaml
@@ -109,6 +127,9 @@ Examples:
   # Pass extra resources Functions in the pipeline can request.
   crossplane beta render xr.yaml composition.yaml functions.yaml \
 	--extra-resources=extra-resources.yaml
+
+  # Remove fields set to null from the rendered resources.
+  crossplane beta render xr.yaml composition.yaml functions.yaml --prune-nulls
 `
 }
 
@@ -120,9 +141,9 @@ func (c *Cmd) AfterApply() error {
 
 // Run render.
 func (c *Cmd) Run(k *kong.Context, log logging.Logger) error { //nolint:gocognit // Only a touch over.
-	xr, err := LoadCompositeResource(c.fs, c.CompositeResource)
+	xrs, err := LoadCompositeResources(c.fs, c.CompositeResource)
 	if err != nil {
-		return errors.Wrapf(err, "cannot load composite resource from %q", c.CompositeResource)
+		return errors.Wrapf(err, "cannot load composite resources from %q", c.CompositeResource)
 	}
 
 	// TODO(negz): Should we do some simple validations, e.g. that the
@@ -180,16 +201,15 @@ func (c *Cmd) Run(k *kong.Context, log logging.Logger) error { //nolint:gocognit
 	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
 	defer cancel()
 
-	out, err := Render(ctx, log, Inputs{
-		CompositeResource: xr,
+	outs, err := RenderEach(ctx, log, Inputs{
 		Composition:       comp,
 		Functions:         fns,
 		ObservedResources: ors,
 		ExtraResources:    ers,
 		Context:           fctx,
-	})
+	}, xrs)
 	if err != nil {
-		return errors.Wrap(err, "cannot render composite resource")
+		return errors.Wrap(err, "cannot render composite resources")
 	}
 
 	// TODO(negz): Right now we're just emitting the desired state, which is an
@@ -199,6 +219,18 @@ func (c *Cmd) Run(k *kong.Context, log logging.Logger) error { //nolint:gocognit
 	// server-side apply would do (e.g. merging vs atomically replacing arrays)
 	// and we don't have enough context (i.e. OpenAPI schemas) to do that.
 
+	// Outputs are grouped by input XR, in the order the XRs were loaded.
+	for i := range outs {
+		if err := c.write(k.Stdout, xrs[i], outs[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write the supplied Outputs, rendered for the supplied XR, as a YAML stream.
+func (c *Cmd) write(w io.Writer, xr *composite.Unstructured, out Outputs) error {
 	s := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{Yaml: true})
 
 	if c.IncludeFullXR {
@@ -221,33 +253,42 @@ func (c *Cmd) Run(k *kong.Context, log logging.Logger) error { //nolint:gocognit
 		}
 	}
 
-	fmt.Fprintln(k.Stdout, "---")
-	if err := s.Encode(out.CompositeResource, os.Stdout); err != nil {
+	fmt.Fprintln(w, "---")
+	if err := s.Encode(c.normalize(&out.CompositeResource.Unstructured), w); err != nil {
 		return errors.Wrapf(err, "cannot marshal composite resource %q to YAML", xr.GetName())
 	}
 
 	for i := range out.ComposedResources {
-		fmt.Fprintln(k.Stdout, "---")
-		if err := s.Encode(&out.ComposedResources[i], os.Stdout); err != nil {
+		fmt.Fprintln(w, "---")
+		if err := s.Encode(c.normalize(&out.ComposedResources[i].Unstructured), w); err != nil {
 			return errors.Wrapf(err, "cannot marshal composed resource %q to YAML", out.ComposedResources[i].GetAnnotations()[AnnotationKeyCompositionResourceName])
 		}
 	}
 
 	if c.IncludeFunctionResults {
 		for i := range out.Results {
-			fmt.Fprintln(k.Stdout, "---")
-			if err := s.Encode(&out.Results[i], os.Stdout); err != nil {
+			fmt.Fprintln(w, "---")
+			if err := s.Encode(&out.Results[i], w); err != nil {
 				return errors.Wrap(err, "cannot marshal result to YAML")
 			}
 		}
 	}
 
 	if c.IncludeContext {
-		fmt.Fprintln(k.Stdout, "---")
-		if err := s.Encode(out.Context, os.Stdout); err != nil {
+		fmt.Fprintln(w, "---")
+		if err := s.Encode(out.Context, w); err != nil {
 			return errors.Wrap(err, "cannot marshal context to YAML")
 		}
 	}
 
 	return nil
 }
+
+// normalize the supplied rendered resource before writing it.
+func (c *Cmd) normalize(u *unstructured.Unstructured) *unstructured.Unstructured {
+	u = normalize.Unstructured(u)
+	if c.PruneNulls {
+		u = normalize.WithoutNulls(u)
+	}
+	return u
+}
@@ -17,12 +17,11 @@ limitations under the License.
 package render
 
 import (
-	"bufio"
-	"io"
 	"path/filepath"
 
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -31,6 +30,7 @@ import (
 
 	apiextensionsv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgv1beta1 "github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
 )
 
 // LoadCompositeResource from a YAML manifest.
@@ -43,11 +43,29 @@ func LoadCompositeResource(fs afero.Fs, file string) (*composite.Unstructured, e
 	return xr, errors.Wrap(yaml.Unmarshal(y, xr), "cannot unmarshal composite resource YAML")
 }
 
+// LoadCompositeResources from a stream of YAML manifests, read from the
+// supplied file or directory.
+func LoadCompositeResources(fs afero.Fs, fileOrDir string) ([]*composite.Unstructured, error) {
+	us, err := LoadManifests(fs, fileOrDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load composite resource manifests")
+	}
+
+	xrs := make([]*composite.Unstructured, 0, len(us))
+	for _, u := range us {
+		xrs = append(xrs, &composite.Unstructured{Unstructured: *u})
+	}
+
+	return xrs, nil
+}
+
 // TODO(negz): What if we load a YAML stream of Compositions? We could then
 // render out nested XRs too. What would that look like in our output? How would
 // we match XRs to Compositions (e.g. selectors, refs etc)
 
-// LoadComposition form a YAML manifest.
+// LoadComposition form a YAML manifest. The manifest may also be a
+// CompositionRevision, in which case it's converted to the Composition it's a
+// revision of.
 func LoadComposition(fs afero.Fs, file string) (*apiextensionsv1.Composition, error) {
 	y, err := afero.ReadFile(fs, file)
 	if err != nil {
@@ -60,26 +78,45 @@ func LoadComposition(fs afero.Fs, file string) (*apiextensionsv1.Composition, er
 	switch gvk := comp.GroupVersionKind(); gvk {
 	case apiextensionsv1.CompositionGroupVersionKind:
 		return comp, nil
+	case apiextensionsv1.CompositionRevisionGroupVersionKind:
+		rev := &apiextensionsv1.CompositionRevision{}
+		if err := yaml.Unmarshal(y, rev); err != nil {
+			return nil, errors.Wrap(err, "cannot unmarshal composition revision resource YAML")
+		}
+		return CompositionFromRevision(rev), nil
 	default:
 		return nil, errors.Errorf("not a composition: %s/%s", gvk.Kind, comp.GetName())
 	}
 }
 
+// CompositionFromRevision returns the Composition the supplied
+// CompositionRevision is a revision of, as it was at that revision.
+func CompositionFromRevision(rev *apiextensionsv1.CompositionRevision) *apiextensionsv1.Composition {
+	conv := apiextensionsv1.GeneratedRevisionSpecConverter{}
+	comp := &apiextensionsv1.Composition{Spec: conv.FromRevisionSpec(rev.Spec)}
+	comp.SetGroupVersionKind(apiextensionsv1.CompositionGroupVersionKind)
+	comp.SetName(rev.GetName())
+	if name := rev.GetLabels()[apiextensionsv1.LabelCompositionName]; name != "" {
+		comp.SetName(name)
+	}
+	return comp
+}
+
 // TODO(negz): Support optionally loading functions and observed resources from
 // a directory of manifests instead of a single stream.
 
 // LoadFunctions from a stream of YAML manifests.
 func LoadFunctions(filesys afero.Fs, file string) ([]pkgv1beta1.Function, error) {
-	stream, err := LoadYAMLStream(filesys, file)
+	us, err := LoadManifests(filesys, file)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot load YAML stream from file")
+		return nil, errors.Wrap(err, "cannot load Function manifests")
 	}
 
-	functions := make([]pkgv1beta1.Function, 0, len(stream))
-	for _, y := range stream {
+	functions := make([]pkgv1beta1.Function, 0, len(us))
+	for _, u := range us {
 		f := &pkgv1beta1.Function{}
-		if err := yaml.Unmarshal(y, f); err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML Function manifest")
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, f); err != nil {
+			return nil, errors.Wrap(err, "cannot parse Function manifest")
 		}
 		switch gvk := f.GroupVersionKind(); gvk {
 		case pkgv1beta1.FunctionGroupVersionKind:
@@ -94,18 +131,14 @@ func LoadFunctions(filesys afero.Fs, file string) ([]pkgv1beta1.Function, error)
 
 // LoadExtraResources from a stream of YAML manifests.
 func LoadExtraResources(fs afero.Fs, file string) ([]unstructured.Unstructured, error) {
-	stream, err := LoadYAMLStream(fs, file)
+	us, err := LoadManifests(fs, file)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot load YAML stream from file")
+		return nil, errors.Wrap(err, "cannot load extra resource manifests")
 	}
 
-	resources := make([]unstructured.Unstructured, 0, len(stream))
-	for _, y := range stream {
-		r := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal(y, r); err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML resource manifest")
-		}
-		resources = append(resources, *r)
+	resources := make([]unstructured.Unstructured, 0, len(us))
+	for _, u := range us {
+		resources = append(resources, *u)
 	}
 
 	return resources, nil
@@ -113,26 +146,22 @@ func LoadExtraResources(fs afero.Fs, file string) ([]unstructured.Unstructured,
 
 // LoadObservedResources from a stream of YAML manifests.
 func LoadObservedResources(fs afero.Fs, file string) ([]composed.Unstructured, error) {
-	stream, err := LoadYAMLStream(fs, file)
+	us, err := LoadManifests(fs, file)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot load YAML stream from file")
+		return nil, errors.Wrap(err, "cannot load observed resource manifests")
 	}
 
-	observed := make([]composed.Unstructured, 0, len(stream))
-	for _, y := range stream {
-		cd := composed.New()
-		if err := yaml.Unmarshal(y, cd); err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML composed resource manifest")
-		}
-		observed = append(observed, *cd)
+	observed := make([]composed.Unstructured, 0, len(us))
+	for _, u := range us {
+		observed = append(observed, composed.Unstructured{Unstructured: *u})
 	}
 
 	return observed, nil
 }
 
-// LoadYAMLStream from the supplied file or directory. Returns an array of byte
-// arrays, where each byte array is expected to be a YAML manifest.
-func LoadYAMLStream(filesys afero.Fs, fileOrDir string) ([][]byte, error) {
+// LoadManifests from the supplied file or directory. Each file can either
+// contain a YAML stream, or a stream of JSON objects and arrays of objects.
+func LoadManifests(filesys afero.Fs, fileOrDir string) ([]*unstructured.Unstructured, error) {
 	var files []string
 	f, err := filesys.Open(fileOrDir)
 	if err != nil {
@@ -151,15 +180,15 @@ func LoadYAMLStream(filesys afero.Fs, fileOrDir string) ([][]byte, error) {
 		}
 		files = append(files, yamls...)
 		if len(files) == 0 {
-			return nil, errors.Errorf("no YAML files found in %q (.yaml or .yml)", fileOrDir)
+			return nil, errors.Errorf("no YAML or JSON files found in %q (.yaml, .yml or .json)", fileOrDir)
 		}
 	}
 
-	out := make([][]byte, 0)
+	out := make([]*unstructured.Unstructured, 0)
 	for i := range files {
-		o, err := LoadYAMLStreamFromFile(filesys, files[i])
+		o, err := loadManifestsFromFile(filesys, files[i])
 		if err != nil {
-			return nil, errors.Wrap(err, "cannot load YAML stream from file")
+			return nil, errors.Wrap(err, "cannot load manifests from file")
 		}
 		out = append(out, o...)
 	}
@@ -167,8 +196,8 @@ func LoadYAMLStream(filesys afero.Fs, fileOrDir string) ([][]byte, error) {
 	return out, nil
 }
 
-// getYAMLFiles returns a list of YAML files from the supplied directory, sorted
-// by file name, ignoring any subdirectory.
+// getYAMLFiles returns a list of YAML (or JSON) files from the supplied
+// directory, sorted by file name, ignoring any subdirectory.
 func getYAMLFiles(fs afero.Fs, dir string) (files []string, err error) {
 	// We don't care about nested directories, so we decided to go with a plain
 	// ReadDir, instead of a Walk.
@@ -187,36 +216,21 @@ func getYAMLFiles(fs afero.Fs, dir string) (files []string, err error) {
 			continue
 		}
 		switch filepath.Ext(entry.Name()) {
-		case ".yaml", ".yml":
+		case ".yaml", ".yml", ".json":
 			files = append(files, filepath.Join(dir, entry.Name()))
 		}
 	}
 	return files, nil
 }
 
-// LoadYAMLStreamFromFile from the supplied file. Returns an array of byte
-// arrays, where each byte array is expected to be a YAML manifest.
-func LoadYAMLStreamFromFile(fs afero.Fs, file string) ([][]byte, error) {
-	out := make([][]byte, 0)
+// loadManifestsFromFile from the supplied file, using the decoder shared by
+// all the commands.
+func loadManifestsFromFile(fs afero.Fs, file string) ([]*unstructured.Unstructured, error) {
 	f, err := fs.Open(file)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot open file")
 	}
 	defer f.Close() //nolint:errcheck // Only open for reading.
-	yr := yaml.NewYAMLReader(bufio.NewReader(f))
 
-	for {
-		bytes, err := yr.Read()
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML stream")
-		}
-		if len(bytes) == 0 {
-			continue
-		}
-		out = append(out, bytes)
-	}
-	return out, nil
+	return manifest.Decode(file, f)
 }
@@ -92,6 +92,74 @@ func TestLoadCompositeResource(t *testing.T) {
 	}
 }
 
+func TestLoadCompositeResources(t *testing.T) {
+	fs := afero.FromIOFS{FS: testdatafs}
+	type want struct {
+		xrs []*composite.Unstructured
+		err error
+	}
+	cases := map[string]struct {
+		file string
+		want want
+	}{
+		"Success": {
+			file: "testdata/xrs.yaml",
+			want: want{
+				xrs: []*composite.Unstructured{
+					{
+						Unstructured: unstructured.Unstructured{
+							Object: MustLoadJSON(`{
+								"apiVersion": "nop.example.org/v1alpha1",
+								"kind": "XNopResource",
+								"metadata": {
+									"name": "test-render-a"
+								},
+								"spec": {
+									"coolField": "I'm cool!"
+								}
+							}`),
+						},
+					},
+					{
+						Unstructured: unstructured.Unstructured{
+							Object: MustLoadJSON(`{
+								"apiVersion": "nop.example.org/v1alpha1",
+								"kind": "XNopResource",
+								"metadata": {
+									"name": "test-render-b"
+								},
+								"spec": {
+									"coolField": "I'm cooler!"
+								}
+							}`),
+						},
+					},
+				},
+			},
+		},
+		"NoSuchFile": {
+			file: "testdata/nonexist.yaml",
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			xrs, err := LoadCompositeResources(fs, tc.file)
+
+			if diff := cmp.Diff(tc.want.xrs, xrs, test.EquateConditions()); diff != "" {
+				t.Errorf("LoadCompositeResources(..), -want, +got:\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("LoadCompositeResources(..), -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestLoadComposition(t *testing.T) {
 	fs := afero.FromIOFS{FS: testdatafs}
 	pipeline := apiextensionsv1.CompositionModePipeline
@@ -127,6 +195,52 @@ func TestLoadComposition(t *testing.T) {
 				},
 			},
 		},
+		"SuccessJSON": {
+			file: "testdata/composition.json",
+			want: want{
+				comp: &apiextensionsv1.Composition{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       apiextensionsv1.CompositionKind,
+						APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{Name: "xnopresources.nop.example.org"},
+					Spec: apiextensionsv1.CompositionSpec{
+						CompositeTypeRef: apiextensionsv1.TypeReference{
+							APIVersion: "nop.example.org/v1alpha1",
+							Kind:       "XNopResource",
+						},
+						Mode: &pipeline,
+						Pipeline: []apiextensionsv1.PipelineStep{{
+							Step:        "be-a-dummy",
+							FunctionRef: apiextensionsv1.FunctionReference{Name: "function-dummy"},
+						}},
+					},
+				},
+			},
+		},
+		"SuccessRevision": {
+			file: "testdata/composition-revision.yaml",
+			want: want{
+				comp: &apiextensionsv1.Composition{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       apiextensionsv1.CompositionKind,
+						APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{Name: "xnopresources.nop.example.org"},
+					Spec: apiextensionsv1.CompositionSpec{
+						CompositeTypeRef: apiextensionsv1.TypeReference{
+							APIVersion: "nop.example.org/v1alpha1",
+							Kind:       "XNopResource",
+						},
+						Mode: &pipeline,
+						Pipeline: []apiextensionsv1.PipelineStep{{
+							Step:        "be-a-dummy",
+							FunctionRef: apiextensionsv1.FunctionReference{Name: "function-dummy"},
+						}},
+					},
+				},
+			},
+		},
 		"NoSuchFile": {
 			file: "testdata/nonexist.yaml",
 			want: want{
@@ -393,13 +507,20 @@ func TestLoadExtraResources(t *testing.T) {
 	}
 }
 
-func TestLoadYAMLStream(t *testing.T) {
+func TestLoadManifests(t *testing.T) {
+	obj := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "example.org/v1",
+			"kind":       "Test",
+			"metadata":   map[string]any{"name": name},
+		}}
+	}
 	type args struct {
 		file string
 		fs   afero.Fs
 	}
 	type want struct {
-		out [][]byte
+		out []*unstructured.Unstructured
 		err error
 	}
 	cases := map[string]struct {
@@ -413,20 +534,56 @@ func TestLoadYAMLStream(t *testing.T) {
 					FS: fstest.MapFS{
 						"testdata/observed.yaml": &fstest.MapFile{
 							Data: []byte(`---
-test: "test"
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: test
 ---
-test: "test2"
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: test2
 `),
 						},
 					},
 				},
 			},
 			want: want{
-				out: [][]byte{
-					[]byte("---\ntest: \"test\"\n"),
-					[]byte("test: \"test2\"\n"),
+				out: []*unstructured.Unstructured{obj("test"), obj("test2")},
+			},
+		},
+		"SuccessJSON": {
+			args: args{
+				file: "testdata/observed.json",
+				fs: afero.FromIOFS{
+					FS: fstest.MapFS{
+						"testdata/observed.json": &fstest.MapFile{
+							Data: []byte(`
+[{"apiVersion": "example.org/v1", "kind": "Test", "metadata": {"name": "test"}}, {"apiVersion": "example.org/v1", "kind": "Test", "metadata": {"name": "test2"}}]
+{"apiVersion": "example.org/v1", "kind": "Test", "metadata": {"name": "test3"}}
+`),
+						},
+					},
 				},
 			},
+			want: want{
+				out: []*unstructured.Unstructured{obj("test"), obj("test2"), obj("test3")},
+			},
+		},
+		"NotAnObject": {
+			args: args{
+				file: "testdata/observed.yaml",
+				fs: afero.FromIOFS{
+					FS: fstest.MapFS{
+						"testdata/observed.yaml": &fstest.MapFile{
+							Data: []byte(`test: "test"`),
+						},
+					},
+				},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
 		},
 		"NoSuchFile": {
 			args: args{
@@ -443,12 +600,18 @@ test: "test2"
 				fs: afero.FromIOFS{FS: fstest.MapFS{
 					"testdata/file-1.yaml": &fstest.MapFile{
 						Data: []byte(`---
-test: "file-1"
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: file-1
 `),
 					},
 					"testdata/file-2.yaml": &fstest.MapFile{
 						Data: []byte(`---
-test: "file-2"
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: file-2
 `),
 					},
 					"testdata/file-3.txt": &fstest.MapFile{
@@ -460,26 +623,21 @@ test: "file-2"
 				}},
 			},
 			want: want{
-				out: [][]byte{
-					[]byte("---\ntest: \"file-1\"\n"),
-					[]byte("---\ntest: \"file-2\"\n"),
-				},
+				out: []*unstructured.Unstructured{obj("file-1"), obj("file-2")},
 			},
 		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			f, err := LoadYAMLStream(tc.args.fs, tc.args.file)
+			got, err := LoadManifests(tc.args.fs, tc.args.file)
 
-			if diff := cmp.Diff(tc.want.out, f, cmpopts.AcyclicTransformer("string", func(in []byte) string {
-				return string(in)
-			})); diff != "" {
-				t.Errorf("LoadYAMLStreamFromFile(..), -want, +got:\n%s", diff)
+			if diff := cmp.Diff(tc.want.out, got); diff != "" {
+				t.Errorf("LoadManifests(..), -want, +got:\n%s", diff)
 			}
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("LoadYAMLStreamFromFile(..), -want, +got:\n%s", diff)
+				t.Errorf("LoadManifests(..), -want, +got:\n%s", diff)
 			}
 		})
 	}
@@ -307,6 +307,41 @@ func Render(ctx context.Context, log logging.Logger, in Inputs) (Outputs, error)
 	return out, nil
 }
 
+// RenderEach renders each of the supplied composite resources using the same
+// Composition, Functions, extra resources and context. Observed composed
+// resources belong to a single composite resource, so when rendering more than
+// one each is only shown the observed resources labelled as composed by it.
+// The returned Outputs are in the same order as the supplied composite
+// resources. The CompositeResource field of the supplied Inputs is ignored.
+func RenderEach(ctx context.Context, log logging.Logger, in Inputs, xrs []*ucomposite.Unstructured) ([]Outputs, error) {
+	outs := make([]Outputs, 0, len(xrs))
+	for _, xr := range xrs {
+		in := in
+		in.CompositeResource = xr
+		if len(xrs) > 1 {
+			in.ObservedResources = getObservedResourcesOf(in.ObservedResources, xr.GetName())
+		}
+		out, err := Render(ctx, log, in)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot render composite resource %q", xr.GetName())
+		}
+		outs = append(outs, out)
+	}
+	return outs, nil
+}
+
+// getObservedResourcesOf returns the supplied observed composed resources
+// labelled as composed by the named composite resource.
+func getObservedResourcesOf(ors []composed.Unstructured, xr string) []composed.Unstructured {
+	out := make([]composed.Unstructured, 0, len(ors))
+	for _, cd := range ors {
+		if cd.GetLabels()[AnnotationKeyCompositeName] == xr {
+			out = append(out, cd)
+		}
+	}
+	return out
+}
+
 // SetComposedResourceMetadata sets standard, required composed resource
 // metadata. It's a simplified version of the same function used by Crossplane.
 // Notably it doesn't handle 'nested' XRs - it assumes the supplied XR should be
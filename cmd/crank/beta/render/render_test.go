@@ -752,6 +752,177 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderEach(t *testing.T) {
+	pipeline := apiextensionsv1.CompositionModePipeline
+
+	lis := NewFunction(t, &fnv1beta1.RunFunctionResponse{
+		Desired: &fnv1beta1.State{
+			Resources: map[string]*fnv1beta1.Resource{
+				"a-cool-resource": {
+					Resource: MustStructJSON(`{
+						"apiVersion": "atest.crossplane.io/v1",
+						"kind": "AComposed"
+					}`),
+					Ready: fnv1beta1.Ready_READY_TRUE,
+				},
+			},
+		},
+	})
+	defer lis.Close()
+
+	in := Inputs{
+		Composition: &apiextensionsv1.Composition{
+			Spec: apiextensionsv1.CompositionSpec{
+				Mode: &pipeline,
+				Pipeline: []apiextensionsv1.PipelineStep{
+					{
+						Step:        "test",
+						FunctionRef: apiextensionsv1.FunctionReference{Name: "function-test"},
+					},
+				},
+			},
+		},
+		Functions: []pkgv1beta1.Function{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "function-test",
+					Annotations: map[string]string{
+						AnnotationKeyRuntime:                  string(AnnotationValueRuntimeDevelopment),
+						AnnotationKeyRuntimeDevelopmentTarget: lis.Addr().String(),
+					},
+				},
+			},
+		},
+	}
+
+	xrs := []*composite.Unstructured{
+		{Unstructured: unstructured.Unstructured{Object: MustLoadJSON(`{
+			"apiVersion": "nop.example.org/v1alpha1",
+			"kind": "XNopResource",
+			"metadata": {"name": "test-render-a"}
+		}`)}},
+		{Unstructured: unstructured.Unstructured{Object: MustLoadJSON(`{
+			"apiVersion": "nop.example.org/v1alpha1",
+			"kind": "XNopResource",
+			"metadata": {"name": "test-render-b"}
+		}`)}},
+	}
+
+	outs, err := RenderEach(context.Background(), logging.NewNopLogger(), in, xrs)
+	if err != nil {
+		t.Fatalf("RenderEach(...): unexpected error: %v", err)
+	}
+
+	got := make([]string, 0, len(outs))
+	for _, out := range outs {
+		got = append(got, out.CompositeResource.GetName())
+		for _, cd := range out.ComposedResources {
+			got = append(got, cd.GetGenerateName()+cd.GetAnnotations()[AnnotationKeyCompositionResourceName])
+		}
+	}
+
+	want := []string{
+		"test-render-a",
+		"test-render-a-a-cool-resource",
+		"test-render-b",
+		"test-render-b-a-cool-resource",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RenderEach(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestRenderEachObservedResources(t *testing.T) {
+	pipeline := apiextensionsv1.CompositionModePipeline
+
+	lis := NewFunction(t, &fnv1beta1.RunFunctionResponse{
+		Desired: &fnv1beta1.State{
+			Resources: map[string]*fnv1beta1.Resource{
+				"a-cool-resource": {
+					Resource: MustStructJSON(`{
+						"apiVersion": "atest.crossplane.io/v1",
+						"kind": "AComposed"
+					}`),
+					Ready: fnv1beta1.Ready_READY_TRUE,
+				},
+			},
+		},
+	})
+	defer lis.Close()
+
+	in := Inputs{
+		Composition: &apiextensionsv1.Composition{
+			Spec: apiextensionsv1.CompositionSpec{
+				Mode: &pipeline,
+				Pipeline: []apiextensionsv1.PipelineStep{
+					{
+						Step:        "test",
+						FunctionRef: apiextensionsv1.FunctionReference{Name: "function-test"},
+					},
+				},
+			},
+		},
+		Functions: []pkgv1beta1.Function{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "function-test",
+					Annotations: map[string]string{
+						AnnotationKeyRuntime:                  string(AnnotationValueRuntimeDevelopment),
+						AnnotationKeyRuntimeDevelopmentTarget: lis.Addr().String(),
+					},
+				},
+			},
+		},
+		// Only the first XR has observed composed resources.
+		ObservedResources: []composed.Unstructured{
+			{Unstructured: unstructured.Unstructured{Object: MustLoadJSON(`{
+				"apiVersion": "atest.crossplane.io/v1",
+				"kind": "AComposed",
+				"metadata": {
+					"name": "test-render-a-xyz",
+					"labels": {"crossplane.io/composite": "test-render-a"},
+					"annotations": {"crossplane.io/composition-resource-name": "a-cool-resource"}
+				}
+			}`)}},
+		},
+	}
+
+	xrs := []*composite.Unstructured{
+		{Unstructured: unstructured.Unstructured{Object: MustLoadJSON(`{
+			"apiVersion": "nop.example.org/v1alpha1",
+			"kind": "XNopResource",
+			"metadata": {"name": "test-render-a"}
+		}`)}},
+		{Unstructured: unstructured.Unstructured{Object: MustLoadJSON(`{
+			"apiVersion": "nop.example.org/v1alpha1",
+			"kind": "XNopResource",
+			"metadata": {"name": "test-render-b"}
+		}`)}},
+	}
+
+	outs, err := RenderEach(context.Background(), logging.NewNopLogger(), in, xrs)
+	if err != nil {
+		t.Fatalf("RenderEach(...): unexpected error: %v", err)
+	}
+
+	// Desired composed resources keep the name of the observed ones, so only
+	// the ones of the first XR are named.
+	got := make([]string, 0, len(outs))
+	for _, out := range outs {
+		for _, cd := range out.ComposedResources {
+			got = append(got, out.CompositeResource.GetName()+"/"+cd.GetName())
+		}
+	}
+
+	want := []string{
+		"test-render-a/test-render-a-xyz",
+		"test-render-b/",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RenderEach(...): -want, +got:\n%s", diff)
+	}
+}
+
 func NewFunction(t *testing.T, rsp *fnv1beta1.RunFunctionResponse) net.Listener {
 	t.Helper()
 
@@ -19,11 +19,15 @@ package printer
 import (
 	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"time"
 
 	gcrname "github.com/google/go-containerregistry/pkg/name"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/cli-runtime/pkg/printers"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -45,6 +49,18 @@ const (
 // DefaultPrinter defines the DefaultPrinter configuration.
 type DefaultPrinter struct {
 	wide bool
+
+	// maxChildren is the maximum number of children printed per parent, the
+	// remaining ones are summarized in a single row. Zero means no limit.
+	maxChildren int
+
+	// fields are the optional fields to print in addition to the default
+	// ones, see AllowedFields.
+	fields []string
+
+	// now returns the current time, used to compute the age of resources.
+	// Defaults to time.Now if not set.
+	now func() time.Time
 }
 
 var _ Printer = &DefaultPrinter{}
@@ -55,6 +71,12 @@ type defaultPrinterRow struct {
 	// wide only fields
 	resourceName string
 
+	// optional fields
+	showCompositionRevision bool
+	compositionRevision     string
+	showAge                 bool
+	age                     string
+
 	name   string
 	synced string
 	ready  string
@@ -68,11 +90,17 @@ func (r *defaultPrinterRow) String() string {
 	if r.wide {
 		cols = append(cols, r.resourceName)
 	}
+	if r.showCompositionRevision {
+		cols = append(cols, r.compositionRevision)
+	}
 	cols = append(cols,
 		r.synced,
 		r.ready,
-		r.status,
 	)
+	if r.showAge {
+		cols = append(cols, r.age)
+	}
+	cols = append(cols, r.status)
 	return strings.Join(cols, "\t")
 }
 
@@ -107,7 +135,7 @@ func (r *defaultPkgPrinterRow) String() string {
 	return strings.Join(cols, "\t") + "\t"
 }
 
-func getHeaders(gk schema.GroupKind, wide bool) (headers fmt.Stringer, isPackageOrPackageRevision bool) {
+func getHeaders(gk schema.GroupKind, wide bool, fields []string) (headers fmt.Stringer, isPackageOrPackageRevision bool) {
 	if xpkg.IsPackageType(gk) || xpkg.IsPackageRevisionType(gk) {
 		return &defaultPkgPrinterRow{
 			wide: wide,
@@ -122,12 +150,16 @@ func getHeaders(gk schema.GroupKind, wide bool) (headers fmt.Stringer, isPackage
 		}, true
 	}
 	return &defaultPrinterRow{
-		wide:         wide,
-		name:         "NAME",
-		resourceName: "RESOURCE",
-		synced:       "SYNCED",
-		ready:        "READY",
-		status:       "STATUS",
+		wide:                    wide,
+		showCompositionRevision: slices.Contains(fields, FieldCompositionRevision),
+		showAge:                 slices.Contains(fields, FieldAge),
+		name:                    "NAME",
+		resourceName:            "RESOURCE",
+		compositionRevision:     "REVISION",
+		age:                     "AGE",
+		synced:                  "SYNCED",
+		ready:                   "READY",
+		status:                  "STATUS",
 	}, false
 }
 
@@ -136,70 +168,100 @@ func getHeaders(gk schema.GroupKind, wide bool) (headers fmt.Stringer, isPackage
 func (p *DefaultPrinter) Print(w io.Writer, root *resource.Resource) error {
 	tw := printers.GetNewTabWriter(w)
 
-	headers, isPackageOrRevision := getHeaders(root.Unstructured.GroupVersionKind().GroupKind(), p.wide)
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+
+	headers, isPackageOrRevision := getHeaders(root.Unstructured.GroupVersionKind().GroupKind(), p.wide, p.fields)
 
 	if _, err := fmt.Fprintln(tw, headers.String()); err != nil {
 		return errors.Wrap(err, errWriteHeader)
 	}
 
-	type queueItem struct {
-		resource *resource.Resource
-		depth    int
-		isLast   bool
-		prefix   string
-	}
+	// The prefix inherited by the children of each node to show the tree
+	// structure, and the number of its children visited so far.
+	childPrefixes := map[*resource.Resource]string{}
+	visited := map[*resource.Resource]int{}
 
-	// Initialize LIFO queue with root element to traverse the tree depth-first,
-	// enqueuing children in reverse order so that they are dequeued in the
-	// right order w.r.t. the way they are defined by the resources.
-	queue := []*queueItem{{resource: root}}
+	// Nodes not printed because they exceed the maximum number of children of
+	// their parent, their children aren't printed either.
+	hidden := map[*resource.Resource]bool{}
 
-	for len(queue) > 0 {
-		var item *queueItem
-		l := len(queue)
-		item, queue = queue[l-1], queue[:l-1] // Pop the last element
+	var err error
+	resource.Walk(root, func(r *resource.Resource, depth int, parent *resource.Resource) {
+		// Reset the state of the node, as nodes shared by multiple parents are
+		// visited once per parent.
+		childPrefixes[r], visited[r], hidden[r] = "", 0, false
+		if err != nil {
+			return
+		}
 
 		// Build the name of the current node, prepending the required prefix to
-		// show the tree structure
+		// show the tree structure. We don't need a prefix for the root, nor a
+		// custom prefix for its children.
 		name := strings.Builder{}
-		childPrefix := item.prefix // Inherited prefix for all the children of the current node
-		switch {
-		case item.depth == 0:
-			// We don't need a prefix for the root, nor a custom
-			// prefix for its children
-		case item.isLast:
-			name.WriteString(item.prefix + "└─ ")
-			childPrefix += "   "
-		default:
-			name.WriteString(item.prefix + "├─ ")
-			childPrefix += "│  "
+		parentCluster := ""
+		if depth > 0 {
+			if hidden[parent] {
+				hidden[r] = true
+				return
+			}
+			parentCluster = parent.Cluster
+
+			idx := visited[parent]
+			visited[parent]++
+			prefix := childPrefixes[parent]
+
+			// Only print the first maxChildren children, if set, summarizing the
+			// remaining ones in a last row.
+			if p.maxChildren > 0 && idx >= p.maxChildren {
+				hidden[r] = true
+				if idx > p.maxChildren {
+					return
+				}
+				name.WriteString(prefix + "└─ ")
+				name.WriteString(fmt.Sprintf("... and %d more", len(parent.Children)-p.maxChildren))
+				if _, err = fmt.Fprintln(tw, getSummaryRow(name.String(), isPackageOrRevision, p.wide, p.fields).String()); err != nil {
+					err = errors.Wrap(err, errWriteRow)
+				}
+				return
+			}
+
+			if idx == len(parent.Children)-1 {
+				name.WriteString(prefix + "└─ ")
+				childPrefixes[r] = prefix + "   "
+			} else {
+				name.WriteString(prefix + "├─ ")
+				childPrefixes[r] = prefix + "│  "
+			}
 		}
 
-		name.WriteString(fmt.Sprintf("%s/%s", item.resource.Unstructured.GetKind(), item.resource.Unstructured.GetName()))
+		name.WriteString(fmt.Sprintf("%s/%s", r.Unstructured.GetKind(), r.Unstructured.GetName()))
 
 		// Append the namespace if it's not empty
-		if item.resource.Unstructured.GetNamespace() != "" {
-			name.WriteString(fmt.Sprintf(" (%s)", item.resource.Unstructured.GetNamespace()))
+		if r.Unstructured.GetNamespace() != "" {
+			name.WriteString(fmt.Sprintf(" (%s)", r.Unstructured.GetNamespace()))
+		}
+
+		// Mark resources fetched from a different cluster than their parent
+		if c := r.Cluster; c != "" && c != parentCluster {
+			name.WriteString(fmt.Sprintf(" [cluster: %s]", c))
 		}
 
 		var row fmt.Stringer
 		if isPackageOrRevision {
-			row = getPkgResourceStatus(item.resource, name.String(), p.wide)
+			row = getPkgResourceStatus(r, name.String(), p.wide)
 		} else {
-			row = getResourceStatus(item.resource, name.String(), p.wide)
+			row = getResourceStatus(r, name.String(), p.wide, p.fields, now)
 		}
 
-		if _, err := fmt.Fprintln(tw, row.String()); err != nil {
-			return errors.Wrap(err, errWriteRow)
-		}
-
-		// Enqueue the children of the current node in reverse order to ensure
-		// that they are dequeued from the LIFO queue in the same order w.r.t.
-		// the way they are defined by the resources.
-		for idx := len(item.resource.Children) - 1; idx >= 0; idx-- {
-			isLast := idx == len(item.resource.Children)-1
-			queue = append(queue, &queueItem{resource: item.resource.Children[idx], depth: item.depth + 1, isLast: isLast, prefix: childPrefix})
+		if _, err = fmt.Fprintln(tw, row.String()); err != nil {
+			err = errors.Wrap(err, errWriteRow)
 		}
+	})
+	if err != nil {
+		return err
 	}
 
 	if err := tw.Flush(); err != nil {
@@ -209,11 +271,25 @@ func (p *DefaultPrinter) Print(w io.Writer, root *resource.Resource) error {
 	return nil
 }
 
+// getSummaryRow returns a row with just the supplied name, used to summarize
+// the children not printed.
+func getSummaryRow(name string, isPackageOrRevision, wide bool, fields []string) fmt.Stringer {
+	if isPackageOrRevision {
+		return &defaultPkgPrinterRow{wide: wide, name: name}
+	}
+	return &defaultPrinterRow{wide: wide, showCompositionRevision: slices.Contains(fields, FieldCompositionRevision), showAge: slices.Contains(fields, FieldAge), name: name}
+}
+
 // getResourceStatus returns a string that represents an entire row of status
-// information for the resource.
-func getResourceStatus(r *resource.Resource, name string, wide bool) fmt.Stringer {
+// information for the resource, computing its age relative to now.
+func getResourceStatus(r *resource.Resource, name string, wide bool, fields []string, now time.Time) fmt.Stringer {
 	readyCond := r.GetCondition(xpv1.TypeReady)
 	syncedCond := r.GetCondition(xpv1.TypeSynced)
+	if r.Unstructured.GroupVersionKind().GroupKind() == (schema.GroupKind{Group: "apps", Kind: "Deployment"}) {
+		// Deployments, e.g. the ones running providers, report readiness
+		// through the Available condition.
+		readyCond = r.GetCondition(xpv1.ConditionType(appsv1.DeploymentAvailable))
+	}
 	var status, m string
 	switch {
 	case r.Error != nil:
@@ -252,13 +328,21 @@ func getResourceStatus(r *resource.Resource, name string, wide bool) fmt.Stringe
 		status = fmt.Sprintf("%s: %s", status, m)
 	}
 
+	// Only composite resources reference a composition revision, leave it
+	// empty for all the others.
+	revision, _ := fieldpath.Pave(r.Unstructured.Object).GetString("spec.compositionRevisionRef.name")
+
 	return &defaultPrinterRow{
-		wide:         wide,
-		name:         name,
-		resourceName: r.Unstructured.GetAnnotations()[composite.AnnotationKeyCompositionResourceName],
-		ready:        mapEmptyStatusToDash(readyCond.Status),
-		synced:       mapEmptyStatusToDash(syncedCond.Status),
-		status:       status,
+		wide:                    wide,
+		showCompositionRevision: slices.Contains(fields, FieldCompositionRevision),
+		name:                    name,
+		resourceName:            r.Unstructured.GetAnnotations()[composite.AnnotationKeyCompositionResourceName],
+		compositionRevision:     revision,
+		showAge:                 slices.Contains(fields, FieldAge),
+		age:                     getAge(r, now),
+		ready:                   mapEmptyStatusToDash(readyCond.Status),
+		synced:                  mapEmptyStatusToDash(syncedCond.Status),
+		status:                  status,
 	}
 }
 
@@ -360,6 +444,17 @@ func getPkgResourceStatus(r *resource.Resource, name string, wide bool) fmt.Stri
 	}
 }
 
+// getAge returns how long the resource has existed at the supplied time, as a
+// compact duration like kubectl does, e.g. 3m or 2d. It's empty if the
+// resource has no creation timestamp, e.g. because it couldn't be fetched.
+func getAge(r *resource.Resource, now time.Time) string {
+	ts := r.Unstructured.GetCreationTimestamp()
+	if ts.IsZero() {
+		return ""
+	}
+	return duration.HumanDuration(now.Sub(ts.Time))
+}
+
 func mapEmptyStatusToDash(s corev1.ConditionStatus) string {
 	if s == "" {
 		return "-"
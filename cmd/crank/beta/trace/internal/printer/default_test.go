@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -30,8 +31,11 @@ import (
 
 func TestDefaultPrinter(t *testing.T) {
 	type args struct {
-		resource *resource.Resource
-		wide     bool
+		resource    *resource.Resource
+		wide        bool
+		maxChildren int
+		fields      []string
+		now         time.Time
 	}
 
 	type want struct {
@@ -64,6 +68,127 @@ ObjectStorage/test-resource (default)                  True      True
    │  └─ User/test-resource-child-2-bucket-hash        True      False   SomethingWrongHappened: Error with bucket child 2
    │     └─ User/test-resource-child-2-1-bucket-hash   True      -       
    └─ User/test-resource-user-hash                     Unknown   True    
+`,
+				err: nil,
+			},
+		},
+		"ResourceWithChildrenMaxChildren": {
+			reason: "Should print at most maxChildren children per resource, summarizing the others.",
+			args: args{
+				resource:    GetComplexResource(),
+				wide:        false,
+				maxChildren: 1,
+			},
+			want: want{
+				// Note: Use spaces instead of tabs for indentation
+				//nolint:dupword // False positive for 'True True'
+				output: `
+NAME                                              SYNCED   READY   STATUS
+ObjectStorage/test-resource (default)             True     True    
+└─ XObjectStorage/test-resource-hash              True     True    
+   ├─ Bucket/test-resource-bucket-hash            True     True    
+   │  ├─ User/test-resource-child-1-bucket-hash   True     False   SomethingWrongHappened: ...rure magna. Non cillum id nulla. Anim culpa do duis consectetur.
+   │  └─ ... and 2 more                                            
+   └─ ... and 1 more                                               
+`,
+				err: nil,
+			},
+		},
+		"ResourceWithCrossClusterChildren": {
+			reason: "Should mark resources fetched from a different cluster than their parent.",
+			args: args{
+				resource: &resource.Resource{
+					Unstructured: DummyClusterScopedResource("XR", "root"),
+					Children: []*resource.Resource{{
+						Unstructured: DummyClusterScopedResource("Object", "xr-object"),
+						Children: []*resource.Resource{{
+							Unstructured: DummyClusterScopedResource("XR", "remote-xr"),
+							Cluster:      "spoke",
+							Children: []*resource.Resource{{
+								Unstructured: DummyClusterScopedResource("Bucket", "bucket"),
+								Cluster:      "spoke",
+							}},
+						}},
+					}},
+				},
+			},
+			want: want{
+				// Note: Use spaces instead of tabs for indentation
+				output: `
+NAME                                  SYNCED   READY   STATUS
+XR/root                               -        -       
+└─ Object/xr-object                   -        -       
+   └─ XR/remote-xr [cluster: spoke]   -        -       
+      └─ Bucket/bucket                -        -       
+`,
+				err: nil,
+			},
+		},
+		"ResourceWithCompositionRevision": {
+			reason: "Should print the composition revision of composite resources if requested.",
+			args: args{
+				resource: &resource.Resource{
+					Unstructured: DummyClusterScopedResource("XR", "root"),
+					Children: []*resource.Resource{{
+						Unstructured: DummyManifest("XNested", "nested", WithCompositionRevisionRef("xnested-7f1c2d")),
+						Children: []*resource.Resource{{
+							Unstructured: DummyClusterScopedResource("Bucket", "bucket"),
+						}},
+					}},
+				},
+				fields: []string{FieldCompositionRevision},
+			},
+			want: want{
+				// Note: Use spaces instead of tabs for indentation
+				output: `
+NAME                  REVISION         SYNCED   READY   STATUS
+XR/root                                -        -       
+└─ XNested/nested     xnested-7f1c2d   -        -       
+   └─ Bucket/bucket                    -        -       
+`,
+				err: nil,
+			},
+		},
+		"ResourceWithoutCompositionRevision": {
+			reason: "Should not print the composition revision of composite resources if not requested.",
+			args: args{
+				resource: &resource.Resource{
+					Unstructured: DummyClusterScopedResource("XR", "root"),
+					Children: []*resource.Resource{{
+						Unstructured: DummyManifest("XNested", "nested", WithCompositionRevisionRef("xnested-7f1c2d")),
+					}},
+				},
+			},
+			want: want{
+				// Note: Use spaces instead of tabs for indentation
+				output: `
+NAME                SYNCED   READY   STATUS
+XR/root             -        -       
+└─ XNested/nested   -        -       
+`,
+				err: nil,
+			},
+		},
+		"ResourceWithAge": {
+			reason: "Should print the age of resources if requested, leaving it empty for resources without a creation timestamp.",
+			args: args{
+				resource: &resource.Resource{
+					Unstructured: DummyManifest("XR", "root", WithCreationTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))),
+					Children: []*resource.Resource{
+						{Unstructured: DummyManifest("Bucket", "bucket", WithCreationTimestamp(time.Date(2024, 1, 2, 23, 57, 0, 0, time.UTC)))},
+						{Unstructured: DummyManifest("User", "user")},
+					},
+				},
+				fields: []string{FieldAge},
+				now:    time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			want: want{
+				// Note: Use spaces instead of tabs for indentation
+				output: `
+NAME               SYNCED   READY   AGE   STATUS
+XR/root            -        -       2d    
+├─ Bucket/bucket   -        -       3m    
+└─ User/user       -        -             
 `,
 				err: nil,
 			},
@@ -139,7 +264,10 @@ Configuration/platform-ref-aws                                               xpk
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			p := DefaultPrinter{
-				wide: tc.args.wide,
+				wide:        tc.args.wide,
+				maxChildren: tc.args.maxChildren,
+				fields:      tc.args.fields,
+				now:         func() time.Time { return tc.args.now },
 			}
 			var buf bytes.Buffer
 			err := p.Print(&buf, tc.args.resource)
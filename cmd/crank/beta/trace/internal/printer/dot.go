@@ -7,6 +7,7 @@ import (
 
 	"github.com/emicklei/dot"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
@@ -90,52 +91,57 @@ func (r *dotPackageLabel) String() string {
 }
 
 // Print gets all the nodes and then return the graph as a dot format string to the Writer.
+// Edges are directed from parents to their children, and resources that are
+// not ready, or healthy for packages, are colored red.
 func (p *DotPrinter) Print(w io.Writer, root *resource.Resource) error {
-	g := dot.NewGraph(dot.Undirected)
+	g := dot.NewGraph(dot.Directed)
 
-	type queueItem struct {
-		resource *resource.Resource
-		parent   *dot.Node
-	}
-
-	queue := []*queueItem{{root, nil}}
-	var id int
-
-	for len(queue) > 0 {
-		// Dequeue the first element from the start
-		item := queue[0]
-		queue = queue[1:]
+	// Resources shared by multiple parents are drawn once, connected to all
+	// their parents.
+	nodes := map[*resource.Resource]dot.Node{}
 
-		node := g.Node(fmt.Sprintf("%d", id))
-		id++
-
-		if item.parent != nil {
-			g.Edge(*item.parent, node)
+	resource.Walk(root, func(r *resource.Resource, _ int, parent *resource.Resource) {
+		node, seen := nodes[r]
+		if !seen {
+			node = g.Node(fmt.Sprintf("%d", len(nodes)))
+			nodes[r] = node
+		}
+		if parent != nil && len(g.FindEdges(nodes[parent], node)) == 0 {
+			g.Edge(nodes[parent], node)
 		}
+		if seen {
+			return
+		}
+
 		var label fmt.Stringer
-		gk := item.resource.Unstructured.GroupVersionKind().GroupKind()
+		// ready is the condition telling whether the resource is working as
+		// expected, Healthy for packages and Ready for all the others.
+		ready := r.GetCondition(xpv1.TypeReady)
+		gk := r.Unstructured.GroupVersionKind().GroupKind()
 		switch {
 		case xpkg.IsPackageType(gk):
-			pkg, err := fieldpath.Pave(item.resource.Unstructured.Object).GetString("spec.package")
+			ready = r.GetCondition(v1.TypeHealthy)
+			pkg, err := fieldpath.Pave(r.Unstructured.Object).GetString("spec.package")
 			l := &dotPackageLabel{
-				apiVersion: item.resource.Unstructured.GroupVersionKind().GroupVersion().String(),
-				name:       item.resource.Unstructured.GetName(),
+				apiVersion: r.Unstructured.GroupVersionKind().GroupVersion().String(),
+				name:       r.Unstructured.GetName(),
 				pkg:        pkg,
-				installed:  string(item.resource.GetCondition(v1.TypeInstalled).Status),
-				healthy:    string(item.resource.GetCondition(v1.TypeHealthy).Status),
+				installed:  string(r.GetCondition(v1.TypeInstalled).Status),
+				healthy:    string(ready.Status),
 			}
 			if err != nil {
 				l.error = err.Error()
 			}
 			label = l
 		case xpkg.IsPackageRevisionType(gk):
-			pkg, err := fieldpath.Pave(item.resource.Unstructured.Object).GetString("spec.image")
+			ready = r.GetCondition(v1.TypeHealthy)
+			pkg, err := fieldpath.Pave(r.Unstructured.Object).GetString("spec.image")
 			l := &dotPackageLabel{
-				apiVersion: item.resource.Unstructured.GroupVersionKind().GroupVersion().String(),
-				name:       item.resource.Unstructured.GetName(),
+				apiVersion: r.Unstructured.GroupVersionKind().GroupVersion().String(),
+				name:       r.Unstructured.GetName(),
 				pkg:        pkg,
-				healthy:    string(item.resource.GetCondition(v1.TypeHealthy).Status),
-				state:      string(item.resource.GetCondition(v1.TypeHealthy).Reason),
+				healthy:    string(ready.Status),
+				state:      string(ready.Reason),
 			}
 			if err != nil {
 				l.error = err.Error()
@@ -143,21 +149,21 @@ func (p *DotPrinter) Print(w io.Writer, root *resource.Resource) error {
 			label = l
 		default:
 			label = &dotLabel{
-				namespace:  item.resource.Unstructured.GetNamespace(),
-				apiVersion: item.resource.Unstructured.GetObjectKind().GroupVersionKind().GroupVersion().String(),
-				name:       fmt.Sprintf("%s/%s", item.resource.Unstructured.GetKind(), item.resource.Unstructured.GetName()),
-				ready:      string(item.resource.GetCondition(xpv1.TypeReady).Status),
-				synced:     string(item.resource.GetCondition(xpv1.TypeSynced).Status),
+				namespace:  r.Unstructured.GetNamespace(),
+				apiVersion: r.Unstructured.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+				name:       fmt.Sprintf("%s/%s", r.Unstructured.GetKind(), r.Unstructured.GetName()),
+				ready:      string(ready.Status),
+				synced:     string(r.GetCondition(xpv1.TypeSynced).Status),
 			}
 		}
 		node.Label(label.String())
 		node.Attr("penwidth", "2")
-
-		// Push the children to the stack, increasing the depth
-		for _, child := range item.resource.Children {
-			queue = append(queue, &queueItem{child, &node})
+		// Resources without the condition at all, e.g. ProviderConfigs, are
+		// not considered not ready.
+		if r.Error != nil || (ready.Status != "" && ready.Status != corev1.ConditionTrue) {
+			node.Attr("color", "red")
 		}
-	}
+	})
 	dotString := g.String()
 	if dotString == "" {
 		return errors.New("graph is empty")
@@ -20,6 +20,8 @@ package printer
 
 import (
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -28,6 +30,7 @@ import (
 
 const (
 	errFmtUnknownPrinterType = "unknown printer output type: %s"
+	errFmtUnknownField       = "unknown field %q, must be one of: %s"
 )
 
 // Type represents the type of printer.
@@ -41,13 +44,47 @@ const (
 	TypeDot     Type = "dot"
 )
 
+// Optional fields the default and wide printers can print.
+const (
+	FieldCompositionRevision = "compositionrevision"
+	FieldAge                 = "age"
+)
+
+// AllowedFields are the optional fields that can be passed to WithFields.
+var AllowedFields = []string{FieldCompositionRevision, FieldAge}
+
 // Printer implements the interface which is used by all printers in this package.
 type Printer interface {
 	Print(w io.Writer, r *resource.Resource) error
 }
 
+// An Option configures a Printer.
+type Option func(p Printer)
+
+// WithMaxChildren limits the number of children printed per parent to n,
+// summarizing the remaining ones in a single row. It only applies to the
+// default and wide printers, the others always print the whole tree.
+func WithMaxChildren(n int) Option {
+	return func(p Printer) {
+		if dp, ok := p.(*DefaultPrinter); ok {
+			dp.maxChildren = n
+		}
+	}
+}
+
+// WithFields prints the supplied optional fields, see AllowedFields, in
+// addition to the default ones. It only applies to the default and wide
+// printers, the others always print whole resources.
+func WithFields(fields ...string) Option {
+	return func(p Printer) {
+		if dp, ok := p.(*DefaultPrinter); ok {
+			dp.fields = fields
+		}
+	}
+}
+
 // New creates a new printer based on the specified type.
-func New(typeStr string) (Printer, error) {
+func New(typeStr string, opts ...Option) (Printer, error) {
 	var p Printer
 
 	switch Type(typeStr) {
@@ -65,5 +102,17 @@ func New(typeStr string) (Printer, error) {
 		return nil, errors.Errorf(errFmtUnknownPrinterType, typeStr)
 	}
 
+	for _, o := range opts {
+		o(p)
+	}
+
+	if dp, ok := p.(*DefaultPrinter); ok {
+		for _, f := range dp.fields {
+			if !slices.Contains(AllowedFields, f) {
+				return nil, errors.Errorf(errFmtUnknownField, f, strings.Join(AllowedFields, ", "))
+			}
+		}
+	}
+
 	return p, nil
 }
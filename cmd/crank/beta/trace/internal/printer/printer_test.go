@@ -17,6 +17,9 @@ limitations under the License.
 package printer
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -78,6 +81,21 @@ func WithCompositionResourceName(n string) DummyManifestOpt {
 	}
 }
 
+// WithCompositionRevisionRef sets the composition revision referenced by the
+// manifest.
+func WithCompositionRevisionRef(name string) DummyManifestOpt {
+	return func(m *unstructured.Unstructured) {
+		fieldpath.Pave(m.Object).SetValue("spec.compositionRevisionRef.name", name)
+	}
+}
+
+// WithCreationTimestamp sets the creation timestamp of the manifest.
+func WithCreationTimestamp(t time.Time) DummyManifestOpt {
+	return func(m *unstructured.Unstructured) {
+		m.SetCreationTimestamp(metav1.NewTime(t))
+	}
+}
+
 // WithImage sets the image of the manifest.
 func WithImage(image string) DummyManifestOpt {
 	return func(m *unstructured.Unstructured) {
@@ -30,6 +30,10 @@ type Resource struct {
 	Unstructured unstructured.Unstructured `json:"object"`
 	Error        error                     `json:"error,omitempty"`
 	Children     []*Resource               `json:"children,omitempty"`
+
+	// Cluster is the name of the additional cluster the resource was fetched
+	// from, empty for the cluster the trace started from.
+	Cluster string `json:"cluster,omitempty"`
 }
 
 // GetCondition of this resource.
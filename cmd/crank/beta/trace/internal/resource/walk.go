@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+// Walk traverses the tree rooted at the supplied Resource depth first, calling
+// visit for each node before its children, in the order they are defined, with
+// its depth, the root being at depth zero, and its parent, nil for the root.
+func Walk(root *Resource, visit func(node *Resource, depth int, parent *Resource)) {
+	if root == nil {
+		return
+	}
+
+	type queueItem struct {
+		node   *Resource
+		depth  int
+		parent *Resource
+	}
+
+	// Set up a LIFO queue to traverse the resource tree depth first.
+	queue := []queueItem{{node: root}}
+
+	for len(queue) > 0 {
+		// Pop the last element from the queue.
+		l := len(queue)
+		item := queue[l-1]
+		queue = queue[:l-1]
+
+		visit(item.node, item.depth, item.parent)
+
+		// Enqueue the children in reverse order, so that they are dequeued in
+		// the order they are defined.
+		for i := len(item.node.Children) - 1; i >= 0; i-- {
+			queue = append(queue, queueItem{node: item.node.Children[i], depth: item.depth + 1, parent: item.node})
+		}
+	}
+}
+
+// Filter returns a copy of the tree rooted at the supplied Resource, without
+// the nodes keep returns false for, together with their children. The root is
+// always kept.
+func Filter(root *Resource, keep func(node *Resource) bool) *Resource {
+	return Map(root, func(node *Resource) *Resource {
+		if node != root && !keep(node) {
+			return nil
+		}
+		return &Resource{Unstructured: node.Unstructured, Error: node.Error, Cluster: node.Cluster}
+	})
+}
+
+// Map returns a new tree built by calling f for each node of the tree rooted
+// at the supplied Resource. The children of the Resource returned by f are
+// replaced by the ones built from the children of the node. Nodes f returns
+// nil for are dropped, together with their children.
+func Map(root *Resource, f func(node *Resource) *Resource) *Resource {
+	if root == nil {
+		return nil
+	}
+	out := f(root)
+	if out == nil {
+		return nil
+	}
+	// f could return the node itself, save its children before resetting them.
+	children := root.Children
+	out.Children = nil
+	for _, child := range children {
+		if c := Map(child, f); c != nil {
+			out.Children = append(out.Children, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func node(name string, children ...*Resource) *Resource {
+	u := unstructured.Unstructured{}
+	u.SetName(name)
+	return &Resource{Unstructured: u, Children: children}
+}
+
+// getTree returns the following tree:
+//
+//	root
+//	├── a
+//	│   ├── a1
+//	│   └── a2
+//	└── b
+//	    └── b1
+func getTree() *Resource {
+	return node("root",
+		node("a", node("a1"), node("a2")),
+		node("b", node("b1")),
+	)
+}
+
+func TestWalk(t *testing.T) {
+	type args struct {
+		root *Resource
+	}
+	type want struct {
+		visited []string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Nil": {
+			reason: "Should not visit anything for a nil root.",
+			args:   args{root: nil},
+			want:   want{},
+		},
+		"RootOnly": {
+			reason: "Should visit just the root if it has no children.",
+			args:   args{root: node("root")},
+			want:   want{visited: []string{"root@0"}},
+		},
+		"Tree": {
+			reason: "Should visit all the nodes depth first, with their depth and parent.",
+			args:   args{root: getTree()},
+			want: want{visited: []string{
+				"root@0",
+				"a@1<root",
+				"a1@2<a",
+				"a2@2<a",
+				"b@1<root",
+				"b1@2<b",
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			Walk(tc.args.root, func(node *Resource, depth int, parent *Resource) {
+				v := fmt.Sprintf("%s@%d", node.Unstructured.GetName(), depth)
+				if parent != nil {
+					v += "<" + parent.Unstructured.GetName()
+				}
+				got = append(got, v)
+			})
+			if diff := cmp.Diff(tc.want.visited, got); diff != "" {
+				t.Errorf("\n%s\nWalk(...): -want visited, +got visited:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	type args struct {
+		root *Resource
+		keep func(node *Resource) bool
+	}
+	type want struct {
+		tree *Resource
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"KeepAll": {
+			reason: "Should return a copy of the whole tree if all nodes are kept.",
+			args: args{
+				root: getTree(),
+				keep: func(_ *Resource) bool { return true },
+			},
+			want: want{tree: getTree()},
+		},
+		"DropSubtree": {
+			reason: "Should drop the nodes not kept, together with their children.",
+			args: args{
+				root: getTree(),
+				keep: func(node *Resource) bool { return node.Unstructured.GetName() != "a" },
+			},
+			want: want{tree: node("root", node("b", node("b1")))},
+		},
+		"AlwaysKeepRoot": {
+			reason: "Should always keep the root.",
+			args: args{
+				root: getTree(),
+				keep: func(node *Resource) bool { return strings.HasPrefix(node.Unstructured.GetName(), "a") },
+			},
+			want: want{tree: node("root", node("a", node("a1"), node("a2")))},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Filter(tc.args.root, tc.args.keep)
+			if diff := cmp.Diff(tc.want.tree, got); diff != "" {
+				t.Errorf("\n%s\nFilter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	type args struct {
+		root *Resource
+		f    func(node *Resource) *Resource
+	}
+	type want struct {
+		tree *Resource
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MapAll": {
+			reason: "Should replace every node with the result of f, keeping the structure of the tree.",
+			args: args{
+				root: getTree(),
+				f: func(n *Resource) *Resource {
+					return node(strings.ToUpper(n.Unstructured.GetName()))
+				},
+			},
+			want: want{tree: node("ROOT",
+				node("A", node("A1"), node("A2")),
+				node("B", node("B1")),
+			)},
+		},
+		"Identity": {
+			reason: "Should keep the whole tree if f returns the node it's called with.",
+			args: args{
+				root: getTree(),
+				f:    func(n *Resource) *Resource { return n },
+			},
+			want: want{tree: getTree()},
+		},
+		"DropNil": {
+			reason: "Should drop the nodes f returns nil for, together with their children.",
+			args: args{
+				root: getTree(),
+				f: func(n *Resource) *Resource {
+					if n.Unstructured.GetName() == "b" {
+						return nil
+					}
+					return node(n.Unstructured.GetName())
+				},
+			},
+			want: want{tree: node("root", node("a", node("a1"), node("a2")))},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Map(tc.args.root, tc.args.f)
+			if diff := cmp.Diff(tc.want.tree, got); diff != "" {
+				t.Errorf("\n%s\nMap(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ package xrm
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -36,8 +38,21 @@ import (
 // Client to get a Resource with all its children.
 type Client struct {
 	getConnectionSecrets bool
+	getProviderHealth    bool
+
+	// maxDepth is the maximum depth of the tree to traverse, the root being at
+	// depth zero. A negative value means no limit.
+	maxDepth int
+
+	// concurrency is the maximum number of children of a resource fetched in
+	// parallel.
+	concurrency int
 
 	client client.Client
+
+	// clusters are the clients for additional clusters, keyed by the name of
+	// the ProviderConfig used to reach them.
+	clusters map[string]client.Client
 }
 
 // ResourceClientOption is a functional option for a Client.
@@ -50,12 +65,51 @@ func WithConnectionSecrets(v bool) ResourceClientOption {
 	}
 }
 
+// WithProviderHealth is a functional option that sets the client to get the
+// Deployment and Pods running the provider of each managed resource.
+func WithProviderHealth(v bool) ResourceClientOption {
+	return func(c *Client) {
+		c.getProviderHealth = v
+	}
+}
+
+// WithMaxDepth is a functional option that sets the maximum depth of the tree
+// to traverse, zero meaning just the root. A negative value means no limit.
+func WithMaxDepth(n int) ResourceClientOption {
+	return func(c *Client) {
+		c.maxDepth = n
+	}
+}
+
+// WithConcurrency is a functional option that sets the maximum number of
+// children of a resource fetched in parallel.
+func WithConcurrency(n int) ResourceClientOption {
+	return func(c *Client) {
+		c.concurrency = max(n, 1)
+	}
+}
+
+// WithClusters is a functional option that registers clients for additional
+// clusters, keyed by the name of the ProviderConfig provider-kubernetes Objects
+// use to reach them. Resources managed by Objects in those clusters are fetched
+// from there, together with their children.
+func WithClusters(clusters map[string]client.Client) ResourceClientOption {
+	return func(c *Client) {
+		c.clusters = make(map[string]client.Client, len(clusters))
+		for name, cl := range clusters {
+			c.clusters[name] = xpunstructured.NewClient(cl)
+		}
+	}
+}
+
 // NewClient returns a new Client.
 func NewClient(in client.Client, opts ...ResourceClientOption) (*Client, error) {
 	uClient := xpunstructured.NewClient(in)
 
 	c := &Client{
-		client: uClient,
+		client:      uClient,
+		maxDepth:    -1,
+		concurrency: 1,
 	}
 
 	for _, o := range opts {
@@ -67,27 +121,115 @@ func NewClient(in client.Client, opts ...ResourceClientOption) (*Client, error)
 
 // GetResourceTree returns the requested Crossplane Resource and all its children.
 func (kc *Client) GetResourceTree(ctx context.Context, root *resource.Resource) (*resource.Resource, error) {
+	var ph *providerHealth
+	if kc.getProviderHealth {
+		ph = newProviderHealth(kc.client, kc.client.RESTMapper())
+	}
+
+	type queueItem struct {
+		resource *resource.Resource
+		depth    int
+	}
+
 	// Set up a FIFO queue to traverse the resource tree breadth first.
-	queue := []*resource.Resource{root}
+	queue := []queueItem{{resource: root}}
+
+	// Keep track of the resources already enqueued, so that reference cycles
+	// don't cause an endless traversal. Resources seen again are still added
+	// as children, but their children are not looked up again.
+	seen := map[string]bool{resourceKey(root): true}
+	enqueue := func(r *resource.Resource, depth int) {
+		if k := resourceKey(r); !seen[k] {
+			seen[k] = true
+			queue = append(queue, queueItem{resource: r, depth: depth})
+		}
+	}
 
 	for len(queue) > 0 {
 		// Pop the first element from the queue.
-		res := queue[0]
+		item := queue[0]
 		queue = queue[1:]
 
+		// Don't get the children of resources at the maximum depth.
+		if kc.maxDepth >= 0 && item.depth >= kc.maxDepth {
+			continue
+		}
+		res := item.resource
+
+		// Follow Objects managing a resource in another registered cluster,
+		// continuing the tree there.
+		if cluster, ref := getRemoteResourceRef(res); ref != nil {
+			if cl, ok := kc.clusters[cluster]; ok {
+				child := resource.GetResource(ctx, cl, ref)
+				child.Cluster = cluster
+
+				res.Children = append(res.Children, child)
+				enqueue(child, item.depth+1)
+			}
+		}
+
+		// Provider health is only looked up in the cluster the trace
+		// started from, where Crossplane and its providers run.
+		if ph != nil && res.Cluster == "" && isManagedResource(res) {
+			if d := ph.GetDeployment(ctx, res); d != nil {
+				res.Children = append(res.Children, d)
+			}
+			continue
+		}
+
 		refs := getResourceChildrenRefs(res, kc.getConnectionSecrets)
 
-		for i := range refs {
-			child := resource.GetResource(ctx, kc.client, &refs[i])
+		for _, child := range kc.getResources(ctx, kc.clientFor(res.Cluster), refs) {
+			child.Cluster = res.Cluster
 
 			res.Children = append(res.Children, child)
-			queue = append(queue, child)
+			enqueue(child, item.depth+1)
 		}
 	}
 
 	return root, nil
 }
 
+// getResources gets the resources referenced by the supplied refs, fetching up
+// to the configured concurrency in parallel. Resources are returned in the same
+// order as refs.
+func (kc *Client) getResources(ctx context.Context, c client.Client, refs []v1.ObjectReference) []*resource.Resource {
+	out := make([]*resource.Resource, len(refs))
+	sem := make(chan struct{}, kc.concurrency)
+	wg := sync.WaitGroup{}
+	for i := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			out[i] = resource.GetResource(ctx, c, &refs[i])
+		}(i)
+	}
+	wg.Wait()
+	return out
+}
+
+// resourceKey returns a key identifying the supplied resource across all
+// clusters, based on its UID if set.
+func resourceKey(r *resource.Resource) string {
+	if uid := r.Unstructured.GetUID(); uid != "" {
+		return r.Cluster + "/" + string(uid)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", r.Cluster, r.Unstructured.GroupVersionKind().GroupKind(), r.Unstructured.GetNamespace(), r.Unstructured.GetName())
+}
+
+// clientFor returns the client for the supplied cluster, the one the trace
+// started from if empty.
+func (kc *Client) clientFor(cluster string) client.Client {
+	if c, ok := kc.clusters[cluster]; ok {
+		return c
+	}
+	return kc.client
+}
+
 // getResourceChildrenRefs returns the references to the children for the given
 // Resource, assuming it's a Crossplane resource, XR or XRC.
 func getResourceChildrenRefs(r *resource.Resource, getConnectionSecrets bool) []v1.ObjectReference {
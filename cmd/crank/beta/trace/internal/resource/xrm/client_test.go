@@ -17,17 +17,26 @@ limitations under the License.
 package xrm
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	resource2 "github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/resource"
 )
@@ -292,3 +301,428 @@ func TestGetResourceChildrenRefs(t *testing.T) {
 		})
 	}
 }
+
+func buildObject(name, providerConfig string, manifest map[string]any) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "kubernetes.crossplane.io/v1alpha2",
+		"kind":       "Object",
+		"metadata":   map[string]any{"name": name},
+		"spec": map[string]any{
+			"providerConfigRef": map[string]any{"name": providerConfig},
+			"forProvider":       map[string]any{"manifest": manifest},
+		},
+	}}
+}
+
+// mockClusterClient serves the supplied objects by name and namespace.
+func mockClusterClient(objs ...unstructured.Unstructured) *test.MockClient {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			for _, o := range objs {
+				if o.GetName() == key.Name && o.GetNamespace() == key.Namespace {
+					o.DeepCopyInto(obj.(*unstructured.Unstructured)) //nolint:forcetypeassert // We only get unstructured.
+					return nil
+				}
+			}
+			return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+		},
+	}
+}
+
+func TestGetResourceTreeClusters(t *testing.T) {
+	xr := buildXR("xr", withXRRefs(v1.ObjectReference{
+		APIVersion: "kubernetes.crossplane.io/v1alpha2",
+		Kind:       "Object",
+		Name:       "xr-object",
+	}))
+	xr.SetAPIVersion("example.com/v1")
+	xr.SetKind("XR")
+
+	remoteXR := buildXR("remote-xr", withXRRefs(v1.ObjectReference{
+		APIVersion: "example.com/v1",
+		Kind:       "MR",
+		Name:       "remote-mr",
+	}))
+	remoteXR.SetAPIVersion("example.com/v1")
+	remoteXR.SetKind("XR")
+	remoteMR := buildMR("example.com/v1", "MR", "remote-mr").Unstructured
+
+	manifest := map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "XR",
+		"metadata":   map[string]any{"name": "remote-xr"},
+	}
+	spokeObject := buildObject("xr-object", "spoke", manifest)
+	otherObject := buildObject("xr-object", "other", manifest)
+
+	notFound := kerrors.NewNotFound(schema.GroupResource{}, "remote-xr")
+	missingXR := unstructured.Unstructured{}
+	missingXR.SetAPIVersion("example.com/v1")
+	missingXR.SetKind("XR")
+	missingXR.SetName("remote-xr")
+
+	type args struct {
+		hub      client.Client
+		clusters map[string]client.Client
+	}
+	type want struct {
+		tree *resource2.Resource
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FollowObjectToRegisteredCluster": {
+			reason: "Should fetch the resource managed by an Object in the registered cluster, continuing the tree there.",
+			args: args{
+				hub: mockClusterClient(spokeObject),
+				clusters: map[string]client.Client{
+					"spoke": mockClusterClient(*remoteXR, remoteMR),
+				},
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: *xr,
+					Children: []*resource2.Resource{{
+						Unstructured: spokeObject,
+						Children: []*resource2.Resource{{
+							Unstructured: *remoteXR,
+							Cluster:      "spoke",
+							Children: []*resource2.Resource{{
+								Unstructured: remoteMR,
+								Cluster:      "spoke",
+							}},
+						}},
+					}},
+				},
+			},
+		},
+		"IgnoreObjectToUnregisteredCluster": {
+			reason: "Should not follow an Object whose ProviderConfig doesn't match any registered cluster.",
+			args: args{
+				hub: mockClusterClient(otherObject),
+				clusters: map[string]client.Client{
+					"spoke": mockClusterClient(*remoteXR, remoteMR),
+				},
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: *xr,
+					Children: []*resource2.Resource{{
+						Unstructured: otherObject,
+					}},
+				},
+			},
+		},
+		"RemoteResourceNotFound": {
+			reason: "Should report an error for a resource managed by an Object not found in the registered cluster.",
+			args: args{
+				hub: mockClusterClient(spokeObject),
+				clusters: map[string]client.Client{
+					"spoke": mockClusterClient(),
+				},
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: *xr,
+					Children: []*resource2.Resource{{
+						Unstructured: spokeObject,
+						Children: []*resource2.Resource{{
+							Unstructured: missingXR,
+							Error:        notFound,
+							Cluster:      "spoke",
+						}},
+					}},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewClient(tc.args.hub, WithClusters(tc.args.clusters))
+			if err != nil {
+				t.Fatalf("NewClient(...): unexpected error: %v", err)
+			}
+			got, err := c.GetResourceTree(context.Background(), &resource2.Resource{Unstructured: *xr.DeepCopy()})
+			if err != nil {
+				t.Fatalf("\n%s\nGetResourceTree(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.tree, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetResourceTreeMaxDepth(t *testing.T) {
+	build := func(name string, refs ...v1.ObjectReference) unstructured.Unstructured {
+		xr := buildXR(name, withXRRefs(refs...))
+		xr.SetAPIVersion("example.com/v1")
+		xr.SetKind("XR")
+		return *xr
+	}
+	root := build("root",
+		v1.ObjectReference{APIVersion: "example.com/v1", Kind: "MR", Name: "mr-1"},
+		v1.ObjectReference{APIVersion: "example.com/v1", Kind: "XR", Name: "xr-1"},
+	)
+	xr1 := build("xr-1", v1.ObjectReference{APIVersion: "example.com/v1", Kind: "MR", Name: "mr-2"})
+	mr1 := buildMR("example.com/v1", "MR", "mr-1").Unstructured
+	mr2 := buildMR("example.com/v1", "MR", "mr-2").Unstructured
+
+	type args struct {
+		maxDepth int
+	}
+	type want struct {
+		tree    *resource2.Resource
+		fetched []string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoLimit": {
+			reason: "Should traverse the whole tree if the maximum depth is negative.",
+			args: args{
+				maxDepth: -1,
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: root,
+					Children: []*resource2.Resource{
+						{Unstructured: mr1},
+						{Unstructured: xr1, Children: []*resource2.Resource{{Unstructured: mr2}}},
+					},
+				},
+				fetched: []string{"mr-1", "xr-1", "mr-2"},
+			},
+		},
+		"RootOnly": {
+			reason: "Should not get any child if the maximum depth is zero.",
+			args: args{
+				maxDepth: 0,
+			},
+			want: want{
+				tree: &resource2.Resource{Unstructured: root},
+			},
+		},
+		"StopAtMaxDepth": {
+			reason: "Should not get the children of resources at the maximum depth.",
+			args: args{
+				maxDepth: 1,
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: root,
+					Children: []*resource2.Resource{
+						{Unstructured: mr1},
+						{Unstructured: xr1},
+					},
+				},
+				fetched: []string{"mr-1", "xr-1"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var fetched []string
+			hub := mockClusterClient(mr1, xr1, mr2)
+			get := hub.MockGet
+			hub.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+				fetched = append(fetched, key.Name)
+				return get(ctx, key, obj)
+			}
+
+			c, err := NewClient(hub, WithMaxDepth(tc.args.maxDepth))
+			if err != nil {
+				t.Fatalf("NewClient(...): unexpected error: %v", err)
+			}
+			got, err := c.GetResourceTree(context.Background(), &resource2.Resource{Unstructured: *root.DeepCopy()})
+			if err != nil {
+				t.Fatalf("\n%s\nGetResourceTree(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.tree, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.fetched, fetched); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want fetched, +got fetched:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetResourceTreeCycles(t *testing.T) {
+	build := func(name string, refs ...v1.ObjectReference) unstructured.Unstructured {
+		xr := buildXR(name, withXRRefs(refs...))
+		xr.SetAPIVersion("example.com/v1")
+		xr.SetKind("XR")
+		return *xr
+	}
+	refA := v1.ObjectReference{APIVersion: "example.com/v1", Kind: "XR", Name: "xr-a"}
+	refB := v1.ObjectReference{APIVersion: "example.com/v1", Kind: "XR", Name: "xr-b"}
+	refC := v1.ObjectReference{APIVersion: "example.com/v1", Kind: "XR", Name: "xr-c"}
+
+	type args struct {
+		root    unstructured.Unstructured
+		objects []unstructured.Unstructured
+	}
+	type want struct {
+		tree *resource2.Resource
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SelfReference": {
+			reason: "Should not traverse again a resource referencing itself.",
+			args: args{
+				root:    build("xr-a", refA),
+				objects: []unstructured.Unstructured{build("xr-a", refA)},
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: build("xr-a", refA),
+					Children:     []*resource2.Resource{{Unstructured: build("xr-a", refA)}},
+				},
+			},
+		},
+		"Cycle": {
+			reason: "Should record a resource seen again as a child, without traversing it again.",
+			args: args{
+				root: build("xr-a", refB),
+				objects: []unstructured.Unstructured{
+					build("xr-a", refB),
+					build("xr-b", refC),
+					build("xr-c", refA),
+				},
+			},
+			want: want{
+				tree: &resource2.Resource{
+					Unstructured: build("xr-a", refB),
+					Children: []*resource2.Resource{{
+						Unstructured: build("xr-b", refC),
+						Children: []*resource2.Resource{{
+							Unstructured: build("xr-c", refA),
+							Children:     []*resource2.Resource{{Unstructured: build("xr-a", refB)}},
+						}},
+					}},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewClient(mockClusterClient(tc.args.objects...))
+			if err != nil {
+				t.Fatalf("NewClient(...): unexpected error: %v", err)
+			}
+
+			var got *resource2.Resource
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				got, err = c.GetResourceTree(context.Background(), &resource2.Resource{Unstructured: tc.args.root})
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("\n%s\nGetResourceTree(...): did not return", tc.reason)
+			}
+
+			if err != nil {
+				t.Fatalf("\n%s\nGetResourceTree(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.tree, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetResourceTreeConcurrency(t *testing.T) {
+	var refs []v1.ObjectReference
+	var mrs []unstructured.Unstructured
+	var children []*resource2.Resource
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("mr-%d", i)
+		refs = append(refs, v1.ObjectReference{APIVersion: "example.com/v1", Kind: "MR", Name: name})
+		mr := buildMR("example.com/v1", "MR", name).Unstructured
+		mrs = append(mrs, mr)
+		children = append(children, &resource2.Resource{Unstructured: mr})
+	}
+	root := buildXR("root", withXRRefs(refs...))
+	root.SetAPIVersion("example.com/v1")
+	root.SetKind("XR")
+
+	type args struct {
+		concurrency int
+	}
+	type want struct {
+		tree        *resource2.Resource
+		maxInFlight int32
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Serial": {
+			reason: "Should fetch children one at a time if concurrency is one.",
+			args: args{
+				concurrency: 1,
+			},
+			want: want{
+				tree:        &resource2.Resource{Unstructured: *root, Children: children},
+				maxInFlight: 1,
+			},
+		},
+		"Parallel": {
+			reason: "Should fetch children in parallel up to the concurrency, returning them in reference order.",
+			args: args{
+				concurrency: 4,
+			},
+			want: want{
+				tree:        &resource2.Resource{Unstructured: *root, Children: children},
+				maxInFlight: 4,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var inFlight, maxInFlight atomic.Int32
+			hub := mockClusterClient(mrs...)
+			get := hub.MockGet
+			hub.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+				n := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					m := maxInFlight.Load()
+					if n <= m || maxInFlight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				// Give the other fetches time to start, later children
+				// finishing first.
+				time.Sleep(time.Duration(50*(len(refs)-slices.IndexFunc(refs, func(r v1.ObjectReference) bool { return r.Name == key.Name }))) * time.Millisecond)
+				return get(ctx, key, obj)
+			}
+
+			c, err := NewClient(hub, WithConcurrency(tc.args.concurrency))
+			if err != nil {
+				t.Fatalf("NewClient(...): unexpected error: %v", err)
+			}
+			got, err := c.GetResourceTree(context.Background(), &resource2.Resource{Unstructured: *root.DeepCopy()})
+			if err != nil {
+				t.Fatalf("\n%s\nGetResourceTree(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.tree, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.maxInFlight, maxInFlight.Load()); diff != "" {
+				t.Errorf("\n%s\nGetResourceTree(...): -want max in flight, +got max in flight:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
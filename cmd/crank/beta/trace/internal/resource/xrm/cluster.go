@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xrm
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+
+	"github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/resource"
+)
+
+// objectGroupKind is the kind of provider-kubernetes' Object, a managed
+// resource wrapping a manifest applied to the cluster its ProviderConfig
+// points to.
+var objectGroupKind = schema.GroupKind{Group: "kubernetes.crossplane.io", Kind: "Object"}
+
+// getRemoteResourceRef returns the name of the ProviderConfig used by the
+// supplied resource and a reference to the resource it manages in the cluster
+// targeted by that ProviderConfig, if the supplied resource is an Object.
+func getRemoteResourceRef(r *resource.Resource) (string, *v1.ObjectReference) {
+	if r.Error != nil || r.Unstructured.GroupVersionKind().GroupKind() != objectGroupKind {
+		return "", nil
+	}
+	p := fieldpath.Pave(r.Unstructured.Object)
+	pc, err := p.GetString("spec.providerConfigRef.name")
+	if err != nil || pc == "" {
+		return "", nil
+	}
+	manifest := map[string]any{}
+	if err := p.GetValueInto("spec.forProvider.manifest", &manifest); err != nil {
+		return "", nil
+	}
+	m := unstructured.Unstructured{Object: manifest}
+	if m.GetKind() == "" || m.GetName() == "" {
+		return "", nil
+	}
+	return pc, &v1.ObjectReference{
+		APIVersion: m.GetAPIVersion(),
+		Kind:       m.GetKind(),
+		Name:       m.GetName(),
+		Namespace:  m.GetNamespace(),
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xrm
+
+import (
+	"context"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/resource"
+)
+
+const (
+	// labelRevision is the label set by Crossplane on the Deployment and Pods
+	// running a package revision.
+	labelRevision = "pkg.crossplane.io/revision"
+
+	errFmtMapKind      = "cannot find the resource of %s"
+	errFmtGetCRD       = "cannot get custom resource definition %s"
+	errListDeployments = "cannot list provider deployments"
+	errListPods        = "cannot list provider pods"
+)
+
+var (
+	crdGVK            = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+	deploymentListGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DeploymentList"}
+	podListGVK        = schema.GroupVersionKind{Version: "v1", Kind: "PodList"}
+)
+
+// providerHealth looks up the Deployment, and its Pods, running the provider
+// of managed resources.
+type providerHealth struct {
+	client client.Client
+
+	// mapper finds the resource of managed resource kinds, i.e. the name of
+	// their CRD.
+	mapper meta.RESTMapper
+
+	// revisions caches the name of the ProviderRevision that installed each
+	// managed resource kind, empty if it wasn't installed by a provider.
+	revisions map[schema.GroupKind]string
+	// deployments caches the Deployment, with Pods as children, running each
+	// ProviderRevision.
+	deployments map[string]*resource.Resource
+}
+
+func newProviderHealth(c client.Client, m meta.RESTMapper) *providerHealth {
+	return &providerHealth{client: c, mapper: m, revisions: map[schema.GroupKind]string{}, deployments: map[string]*resource.Resource{}}
+}
+
+// isManagedResource returns true if the supplied resource looks like a
+// managed resource.
+func isManagedResource(r *resource.Resource) bool {
+	if r.Error != nil || r.Unstructured.GetNamespace() != "" {
+		return false
+	}
+	p := fieldpath.Pave(r.Unstructured.Object)
+	if _, err := p.GetValue("spec.resourceRefs"); err == nil {
+		return false
+	}
+	_, err := p.GetValue("spec.forProvider")
+	return err == nil
+}
+
+// GetDeployment returns the provider Deployment, with its Pods as children,
+// running the controller of the supplied managed resource. It returns nil if
+// the managed resource wasn't installed by a provider, or if the provider has
+// no Deployment. Errors looking up the provider are set as Resource.Error of
+// the returned Deployment, so that they don't abort the trace.
+func (h *providerHealth) GetDeployment(ctx context.Context, mr *resource.Resource) *resource.Resource {
+	rev, err := h.getRevision(ctx, mr.Unstructured.GroupVersionKind())
+	if err != nil {
+		return deploymentError(err)
+	}
+	if rev == "" {
+		return nil
+	}
+	if d, ok := h.deployments[rev]; ok {
+		return copyDeployment(d)
+	}
+
+	dl := &unstructured.UnstructuredList{}
+	dl.SetGroupVersionKind(deploymentListGVK)
+	if err := h.client.List(ctx, dl, client.MatchingLabels{labelRevision: rev}); err != nil {
+		return deploymentError(errors.Wrap(err, errListDeployments))
+	}
+	if len(dl.Items) == 0 {
+		h.deployments[rev] = nil
+		return nil
+	}
+	d := &resource.Resource{Unstructured: dl.Items[0]}
+
+	pl := &unstructured.UnstructuredList{}
+	pl.SetGroupVersionKind(podListGVK)
+	if err := h.client.List(ctx, pl, client.InNamespace(d.Unstructured.GetNamespace()), client.MatchingLabels{labelRevision: rev}); err != nil {
+		d.Error = errors.Wrap(err, errListPods)
+		return d
+	}
+	for i := range pl.Items {
+		d.Children = append(d.Children, &resource.Resource{Unstructured: pl.Items[i]})
+	}
+
+	h.deployments[rev] = d
+	return copyDeployment(d)
+}
+
+// getRevision returns the name of the ProviderRevision controlling the CRD of
+// the supplied managed resource kind, empty if it wasn't installed by a
+// provider. Only the CRD of the supplied kind is fetched, just its metadata.
+func (h *providerHealth) getRevision(ctx context.Context, gvk schema.GroupVersionKind) (string, error) {
+	gk := gvk.GroupKind()
+	if rev, ok := h.revisions[gk]; ok {
+		return rev, nil
+	}
+
+	m, err := h.mapper.RESTMapping(gk, gvk.Version)
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtMapKind, gk)
+	}
+	name := m.Resource.GroupResource().String()
+
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(crdGVK)
+	err = h.client.Get(ctx, types.NamespacedName{Name: name}, crd)
+	if kerrors.IsNotFound(err) {
+		// Not a custom resource, so not installed by a provider either.
+		h.revisions[gk] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtGetCRD, name)
+	}
+
+	rev := ""
+	for _, ref := range crd.GetOwnerReferences() {
+		if ref.Kind == pkgv1.ProviderRevisionKind && ref.Controller != nil && *ref.Controller {
+			rev = ref.Name
+		}
+	}
+	h.revisions[gk] = rev
+	return rev, nil
+}
+
+// copyDeployment returns a deep copy of the supplied provider Deployment and
+// its Pods, so that each managed resource gets its own node in the tree.
+func copyDeployment(d *resource.Resource) *resource.Resource {
+	return resource.Map(d, func(r *resource.Resource) *resource.Resource {
+		return &resource.Resource{Unstructured: *r.Unstructured.DeepCopy(), Error: r.Error, Cluster: r.Cluster}
+	})
+}
+
+// deploymentError returns a provider Deployment with the supplied error set,
+// standing in for the Deployment that couldn't be looked up.
+func deploymentError(err error) *resource.Resource {
+	u := unstructured.Unstructured{}
+	u.SetGroupVersionKind(deploymentListGVK.GroupVersion().WithKind("Deployment"))
+	return &resource.Resource{Unstructured: u, Error: err}
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xrm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/resource"
+)
+
+func buildMR(apiVersion, kind, name string) *resource.Resource {
+	return &resource.Resource{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+		"spec":       map[string]any{"forProvider": map[string]any{}},
+	}}}
+}
+
+func buildCRD(name, revision string) metav1.PartialObjectMetadata {
+	crd := metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName(name)
+	crd.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: "pkg.crossplane.io/v1",
+		Kind:       "ProviderRevision",
+		Name:       revision,
+		UID:        "some-uid",
+		Controller: ptr.To(true),
+	}})
+	return crd
+}
+
+// buildMapper returns a RESTMapper knowing the supplied cluster scoped kinds.
+func buildMapper(gvks ...schema.GroupVersionKind) meta.RESTMapper {
+	m := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range gvks {
+		m.Add(gvk, meta.RESTScopeRoot)
+	}
+	return m
+}
+
+func buildDeployment(name, available string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": name, "namespace": "crossplane-system"},
+		"status": map[string]any{"conditions": []any{map[string]any{
+			"type":   "Available",
+			"status": available,
+		}}},
+	}}
+}
+
+func buildPod(name, ready string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": name, "namespace": "crossplane-system"},
+		"status": map[string]any{"conditions": []any{map[string]any{
+			"type":   "Ready",
+			"status": ready,
+		}}},
+	}}
+}
+
+// mockProviderClient serves the supplied CRDs metadata, and the supplied
+// Deployments and Pods, the latter indexed by revision label.
+func mockProviderClient(crds []metav1.PartialObjectMetadata, deployments, pods map[string][]unstructured.Unstructured) client.Client {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			for _, crd := range crds {
+				if crd.GetName() == key.Name {
+					crd.DeepCopyInto(obj.(*metav1.PartialObjectMetadata)) //nolint:forcetypeassert // We only get CRDs metadata.
+					return nil
+				}
+			}
+			return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+		},
+		MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			rev := ""
+			if lo.LabelSelector != nil {
+				if v, ok := lo.LabelSelector.RequiresExactMatch(labelRevision); ok {
+					rev = v
+				}
+			}
+			l := obj.(*unstructured.UnstructuredList) //nolint:forcetypeassert // We only list unstructured.
+			switch l.GroupVersionKind() {
+			case deploymentListGVK:
+				l.Items = deployments[rev]
+			case podListGVK:
+				l.Items = pods[rev]
+			default:
+				return errors.New("unexpected list")
+			}
+			return nil
+		},
+	}
+}
+
+// withListError wraps the supplied mock client, failing lists of the supplied
+// kind with the supplied error.
+func withListError(c client.Client, gvk schema.GroupVersionKind, err error) client.Client {
+	mc := c.(*test.MockClient) //nolint:forcetypeassert // We only wrap mock clients.
+	list := mc.MockList
+	mc.MockList = func(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+		if obj.GetObjectKind().GroupVersionKind() == gvk {
+			return err
+		}
+		return list(ctx, obj, opts...)
+	}
+	return mc
+}
+
+func TestProviderHealthGetDeployment(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	crds := []metav1.PartialObjectMetadata{
+		buildCRD("buckets.aws.example.org", "provider-aws-abc"),
+		buildCRD("buckets.gcp.example.org", "provider-gcp-def"),
+	}
+	mapper := buildMapper(
+		schema.GroupVersionKind{Group: "aws.example.org", Version: "v1", Kind: "Bucket"},
+		schema.GroupVersionKind{Group: "gcp.example.org", Version: "v1", Kind: "Bucket"},
+		schema.GroupVersionKind{Group: "azure.example.org", Version: "v1", Kind: "Bucket"},
+	)
+
+	type args struct {
+		client client.Client
+		mr     *resource.Resource
+	}
+	type want struct {
+		deployment *resource.Resource
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"HealthyProvider": {
+			reason: "Should return the available Deployment and ready Pods of the provider.",
+			args: args{
+				client: mockProviderClient(crds,
+					map[string][]unstructured.Unstructured{"provider-aws-abc": {buildDeployment("provider-aws-abc", "True")}},
+					map[string][]unstructured.Unstructured{"provider-aws-abc": {buildPod("provider-aws-abc-1", "True")}}),
+				mr: buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: &resource.Resource{
+					Unstructured: buildDeployment("provider-aws-abc", "True"),
+					Children: []*resource.Resource{
+						{Unstructured: buildPod("provider-aws-abc-1", "True")},
+					},
+				},
+			},
+		},
+		"UnhealthyProvider": {
+			reason: "Should return the unavailable Deployment and the not ready Pods of the provider.",
+			args: args{
+				client: mockProviderClient(crds,
+					map[string][]unstructured.Unstructured{"provider-gcp-def": {buildDeployment("provider-gcp-def", "False")}},
+					map[string][]unstructured.Unstructured{"provider-gcp-def": {buildPod("provider-gcp-def-1", "False"), buildPod("provider-gcp-def-2", "True")}}),
+				mr: buildMR("gcp.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: &resource.Resource{
+					Unstructured: buildDeployment("provider-gcp-def", "False"),
+					Children: []*resource.Resource{
+						{Unstructured: buildPod("provider-gcp-def-1", "False")},
+						{Unstructured: buildPod("provider-gcp-def-2", "True")},
+					},
+				},
+			},
+		},
+		"NoDeployment": {
+			reason: "Should return nothing if the provider has no Deployment.",
+			args: args{
+				client: mockProviderClient(crds, nil, nil),
+				mr:     buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			},
+		},
+		"NotInstalledByProvider": {
+			reason: "Should return nothing if the managed resource kind wasn't installed by a provider.",
+			args: args{
+				client: mockProviderClient(crds, nil, nil),
+				mr:     buildMR("azure.example.org/v1", "Bucket", "bucket"),
+			},
+		},
+		"UnknownKind": {
+			reason: "Should return a Deployment with the error set if the resource of the managed resource kind can't be found.",
+			args: args{
+				client: mockProviderClient(crds, nil, nil),
+				mr:     buildMR("other.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: deploymentError(errors.Wrapf(&meta.NoKindMatchError{
+					GroupKind:        schema.GroupKind{Group: "other.example.org", Kind: "Bucket"},
+					SearchedVersions: []string{"v1"},
+				}, errFmtMapKind, schema.GroupKind{Group: "other.example.org", Kind: "Bucket"})),
+			},
+		},
+		"GetCRDError": {
+			reason: "Should return a Deployment with the error set if the CRD of the managed resource can't be fetched.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				mr:     buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: deploymentError(errors.Wrapf(errBoom, errFmtGetCRD, "buckets.aws.example.org")),
+			},
+		},
+		"ListDeploymentsError": {
+			reason: "Should return a Deployment with the error set if the provider Deployments can't be listed.",
+			args: args{
+				client: withListError(mockProviderClient(crds, nil, nil), deploymentListGVK, errBoom),
+				mr:     buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: deploymentError(errors.Wrap(errBoom, errListDeployments)),
+			},
+		},
+		"ListPodsError": {
+			reason: "Should return the Deployment with the error set if the provider Pods can't be listed.",
+			args: args{
+				client: withListError(mockProviderClient(crds,
+					map[string][]unstructured.Unstructured{"provider-aws-abc": {buildDeployment("provider-aws-abc", "True")}},
+					nil), podListGVK, errBoom),
+				mr: buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			},
+			want: want{
+				deployment: &resource.Resource{
+					Unstructured: buildDeployment("provider-aws-abc", "True"),
+					Error:        errors.Wrap(errBoom, errListPods),
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := newProviderHealth(tc.args.client, mapper).GetDeployment(context.Background(), tc.args.mr)
+			if diff := cmp.Diff(tc.want.deployment, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetDeployment(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProviderHealthGetDeploymentPerManagedResource(t *testing.T) {
+	c := mockProviderClient(
+		[]metav1.PartialObjectMetadata{buildCRD("buckets.aws.example.org", "provider-aws-abc")},
+		map[string][]unstructured.Unstructured{"provider-aws-abc": {buildDeployment("provider-aws-abc", "True")}},
+		map[string][]unstructured.Unstructured{"provider-aws-abc": {buildPod("provider-aws-abc-1", "True")}})
+	h := newProviderHealth(c, buildMapper(schema.GroupVersionKind{Group: "aws.example.org", Version: "v1", Kind: "Bucket"}))
+
+	first := h.GetDeployment(context.Background(), buildMR("aws.example.org/v1", "Bucket", "first"))
+	second := h.GetDeployment(context.Background(), buildMR("aws.example.org/v1", "Bucket", "second"))
+
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("GetDeployment(...): want the same Deployment for managed resources of the same provider, -first, +second:\n%s", diff)
+	}
+	if first == second || first.Children[0] == second.Children[0] {
+		t.Errorf("GetDeployment(...): want each managed resource to get its own copy of the Deployment and its Pods")
+	}
+}
+
+func TestIsManagedResource(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		r      *resource.Resource
+		want   bool
+	}{
+		"ManagedResource": {
+			reason: "Should detect resources with a forProvider field as managed resources.",
+			r:      buildMR("aws.example.org/v1", "Bucket", "bucket"),
+			want:   true,
+		},
+		"Composite": {
+			reason: "Should not detect composite resources as managed resources.",
+			r:      &resource.Resource{Unstructured: *buildXR("xr")},
+			want:   false,
+		},
+		"Claim": {
+			reason: "Should not detect claims as managed resources.",
+			r:      &resource.Resource{Unstructured: *buildXRC("ns", "xrc")},
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isManagedResource(tc.r); got != tc.want {
+				t.Errorf("\n%s\nisManagedResource(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -19,9 +19,15 @@ package trace
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"golang.org/x/term"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/discovery"
@@ -47,6 +53,8 @@ const (
 	errKubeConfig             = "failed to get kubeconfig"
 	errKubeNamespace          = "failed to get namespace from kubeconfig"
 	errInitKubeClient         = "cannot init kubeclient"
+	errFmtClusterKubeConfig   = "failed to get kubeconfig for cluster %q"
+	errFmtInitClusterClient   = "cannot init kubeclient for cluster %q"
 	errGetDiscoveryClient     = "cannot get discovery client"
 	errGetMapping             = "cannot get mapping for resource"
 	errInitPrinter            = "cannot init new printer"
@@ -54,21 +62,37 @@ const (
 	errNameDoubled            = "name provided twice, must be provided separately 'TYPE[.VERSION][.GROUP] [NAME]' or in the 'TYPE[.VERSION][.GROUP][/NAME]' format"
 	errInvalidResource        = "invalid resource, must be provided in the 'TYPE[.VERSION][.GROUP][/NAME]' format"
 	errInvalidResourceAndName = "invalid resource and name"
+	errOpenOutputFile         = "cannot open output file"
+	errCloseOutputFile        = "cannot close output file"
+	errInvalidInterval        = "watch interval must be positive"
 )
 
+// clearScreen moves the cursor to the top left corner of the terminal and
+// clears it.
+const clearScreen = "\033[H\033[2J"
+
 // Cmd builds the trace tree for a Crossplane resource.
 type Cmd struct {
 	Resource string `arg:"" help:"Kind of the Crossplane resource, accepts the 'TYPE[.VERSION][.GROUP][/NAME]' format."`
 	Name     string `arg:"" help:"Name of the Crossplane resource, can be passed as part of the resource too."          optional:""`
 
 	// TODO(phisco): add support for all the usual kubectl flags; configFlags := genericclioptions.NewConfigFlags(true).AddFlags(...)
-	Context                   string `default:""                                    help:"Kubernetes context."                         name:"context"                                                             short:"c"`
-	Namespace                 string `default:""                                    help:"Namespace of the resource."                  name:"namespace"                                                           short:"n"`
-	Output                    string `default:"default"                             enum:"default,wide,json,dot"                       help:"Output format. One of: default, wide, json, dot."                    name:"output"                    short:"o"`
-	ShowConnectionSecrets     bool   `help:"Show connection secrets in the output." name:"show-connection-secrets"                     short:"s"`
-	ShowPackageDependencies   string `default:"unique"                              enum:"unique,all,none"                             help:"Show package dependencies in the output. One of: unique, all, none." name:"show-package-dependencies"`
-	ShowPackageRevisions      string `default:"active"                              enum:"active,all,none"                             help:"Show package revisions in the output. One of: active, all, none."    name:"show-package-revisions"`
-	ShowPackageRuntimeConfigs bool   `default:"false"                               help:"Show package runtime configs in the output." name:"show-package-runtime-configs"`
+	Clusters                  map[string]string `help:"Additional cluster reached by provider-kubernetes Objects, as PROVIDERCONFIG=KUBECONFIG."                          name:"cluster"`
+	Concurrency               int               `default:"5"                                                                                                              help:"Maximum number of children of a resource to fetch in parallel."                             name:"concurrency"`
+	Context                   string            `default:""                                                                                                               help:"Kubernetes context."                                                                        name:"context"                                                             short:"c"`
+	Depth                     int               `default:"-1"                                                                                                             help:"Maximum depth of the tree to show, 0 meaning just the resource. Negative means no limit."   name:"depth"                                                               short:"d"`
+	Fields                    []string          `help:"Comma-separated list of optional fields to show in the default and wide output. One of: compositionrevision, age." name:"fields"                                                                                     placeholder:"FIELD"`
+	IncludeProviderHealth     bool              `help:"Include the Deployment and Pods running the provider of each managed resource in the output."                      name:"include-provider-health"`
+	Interval                  time.Duration     `default:"2s"                                                                                                             help:"Interval between refreshes of the output in watch mode."                                    name:"interval"`
+	MaxChildren               int               `default:"0"                                                                                                              help:"Maximum number of children to show per resource, summarizing the others. 0 means no limit." name:"max-children"`
+	Namespace                 string            `default:""                                                                                                               help:"Namespace of the resource."                                                                 name:"namespace"                                                           short:"n"`
+	Output                    string            `default:"default"                                                                                                        enum:"default,wide,json,dot"                                                                      help:"Output format. One of: default, wide, json, dot."                    name:"output"                    short:"o"`
+	OutputFile                string            `help:"File to write the output to, truncating it if it exists. If not specified, stdout will be used."                   name:"output-file"                                                                                placeholder:"PATH"                                                         type:"path"`
+	ShowConnectionSecrets     bool              `help:"Show connection secrets in the output."                                                                            name:"show-connection-secrets"                                                                    short:"s"`
+	ShowPackageDependencies   string            `default:"unique"                                                                                                         enum:"unique,all,none"                                                                            help:"Show package dependencies in the output. One of: unique, all, none." name:"show-package-dependencies"`
+	ShowPackageRevisions      string            `default:"active"                                                                                                         enum:"active,all,none"                                                                            help:"Show package revisions in the output. One of: active, all, none."    name:"show-package-revisions"`
+	ShowPackageRuntimeConfigs bool              `default:"false"                                                                                                          help:"Show package runtime configs in the output."                                                name:"show-package-runtime-configs"`
+	Watch                     bool              `help:"Watch the resource tree, refreshing the output every interval until interrupted."                                  name:"watch"                                                                                      short:"w"`
 }
 
 // Help returns help message for the trace command.
@@ -91,9 +115,29 @@ Examples:
   # Show connection secrets in the output
   crossplane beta trace mykind my-res -n my-ns --show-connection-secrets
 
+  # Show the health of the providers of the managed resources in the output
+  crossplane beta trace mykind my-res -n my-ns --include-provider-health
+
+  # Show only the requested resource and its direct children
+  crossplane beta trace mykind my-res -n my-ns --depth 1
+
+  # Show at most 5 children per resource, summarizing the others
+  crossplane beta trace mykind my-res -n my-ns --max-children 5
+
+  # Watch the resource tree until interrupted, refreshing it every 5 seconds
+  crossplane beta trace mykind my-res -n my-ns --watch --interval 5s
+
+  # Follow provider-kubernetes Objects using the 'spoke' ProviderConfig to the
+  # cluster they manage resources in
+  crossplane beta trace mykind my-res -n my-ns --cluster spoke=spoke.kubeconfig
+
   # Output a graph in dot format and pipe to dot to generate a png
   crossplane beta trace mykind my-res -n my-ns -o dot | dot -Tpng -o output.png
 
+  # Write a graph in dot format to a file, to render large graphs separately
+  crossplane beta trace mykind my-res -n my-ns -o dot --output-file graph.dot
+  dot -Tsvg graph.dot -o output.svg
+
   # Output all retrieved resources to json and pipe to jq to have it coloured
   crossplane beta trace mykind my-res -n my-ns -o json | jq
 
@@ -107,8 +151,12 @@ func (c *Cmd) Run(k *kong.Context, logger logging.Logger) error {
 	ctx := context.Background()
 	logger = logger.WithValues("Resource", c.Resource, "Name", c.Name)
 
+	if c.Watch && c.Interval <= 0 {
+		return errors.New(errInvalidInterval)
+	}
+
 	// Init new printer
-	p, err := printer.New(c.Output)
+	p, err := printer.New(c.Output, printer.WithMaxChildren(c.MaxChildren), printer.WithFields(c.Fields...))
 	if err != nil {
 		return errors.Wrap(err, errInitPrinter)
 	}
@@ -181,6 +229,11 @@ func (c *Cmd) Run(k *kong.Context, logger logging.Logger) error {
 		return errors.Wrap(err, errGetResource)
 	}
 
+	clusters, err := c.getClusterClients()
+	if err != nil {
+		return err
+	}
+
 	var treeClient resource.TreeClient
 	switch {
 	case xpkg.IsPackageType(mapping.GroupVersionKind.GroupKind()):
@@ -194,27 +247,124 @@ func (c *Cmd) Run(k *kong.Context, logger logging.Logger) error {
 		}
 	default:
 		logger.Debug("Requested resource is not a package, assumed to be an XR, XRC or MR")
-		treeClient, err = xrm.NewClient(client, xrm.WithConnectionSecrets(c.ShowConnectionSecrets))
+		treeClient, err = xrm.NewClient(client,
+			xrm.WithConnectionSecrets(c.ShowConnectionSecrets),
+			xrm.WithProviderHealth(c.IncludeProviderHealth),
+			xrm.WithMaxDepth(c.Depth),
+			xrm.WithConcurrency(c.Concurrency),
+			xrm.WithClusters(clusters))
 		if err != nil {
 			return errors.Wrap(err, errInitKubeClient)
 		}
 	}
 	logger.Debug("Built client")
 
-	root, err = treeClient.GetResourceTree(ctx, root)
-	if err != nil {
-		logger.Debug(errGetResource, "error", err)
-		return errors.Wrap(err, errGetResource)
+	// render gets the resource tree and prints it. Getting the tree adds
+	// children to the root resource, so it's fetched again for each render
+	// but the first one.
+	render := func(ctx context.Context) error {
+		if root == nil {
+			root = resource.GetResource(ctx, client, rootRef)
+			if err := root.Error; err != nil {
+				return errors.Wrap(err, errGetResource)
+			}
+		}
+		tree, err := treeClient.GetResourceTree(ctx, root)
+		root = nil
+		if err != nil {
+			logger.Debug(errGetResource, "error", err)
+			return errors.Wrap(err, errGetResource)
+		}
+		logger.Debug("Got resource tree", "root", tree)
+
+		// Print resources
+		return errors.Wrap(c.print(k.Stdout, p, tree), errCliOutput)
+	}
+
+	if !c.Watch {
+		return render(ctx)
 	}
-	logger.Debug("Got resource tree", "root", root)
 
-	// Print resources
-	err = p.Print(k.Stdout, root)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	screen := io.Discard
+	if c.clearsScreen(k.Stdout) {
+		screen = k.Stdout
+	}
+	return watch(ctx, screen, c.Interval, render)
+}
+
+// clearsScreen returns true if the screen should be cleared before each
+// refresh in watch mode. That's only the case if a human readable output is
+// written to a terminal, json and dot outputs are usually piped to another
+// program, or written to a file, which the escape sequence would corrupt.
+func (c *Cmd) clearsScreen(stdout io.Writer) bool {
+	if c.OutputFile != "" || (c.Output != "default" && c.Output != "wide") {
+		return false
+	}
+	f, ok := stdout.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// watch calls render every interval, clearing the supplied screen before each
+// call, until the supplied context is done.
+func watch(ctx context.Context, screen io.Writer, interval time.Duration, render func(ctx context.Context) error) error {
+	for {
+		if _, err := fmt.Fprint(screen, clearScreen); err != nil {
+			return errors.Wrap(err, errCliOutput)
+		}
+		if err := render(ctx); err != nil {
+			// Rendering is expected to fail if interrupted.
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// print prints the supplied resource tree to the output file, if any, or to
+// the supplied writer otherwise.
+func (c *Cmd) print(stdout io.Writer, p printer.Printer, root *resource.Resource) (err error) {
+	if c.OutputFile == "" {
+		return p.Print(stdout, root)
+	}
+	f, err := os.OpenFile(c.OutputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
 	if err != nil {
-		return errors.Wrap(err, errCliOutput)
+		return errors.Wrap(err, errOpenOutputFile)
 	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, errCloseOutputFile)
+		}
+	}()
+	return p.Print(f, root)
+}
 
-	return nil
+// getClusterClients returns a client for each additional cluster, keyed by
+// the name of the ProviderConfig used to reach it.
+func (c *Cmd) getClusterClients() (map[string]client.Client, error) {
+	clients := make(map[string]client.Client, len(c.Clusters))
+	for name, path := range c.Clusters {
+		cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+			&clientcmd.ConfigOverrides{},
+		).ClientConfig()
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtClusterKubeConfig, name)
+		}
+		cl, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtInitClusterClient, name)
+		}
+		clients[name] = cl
+	}
+	return clients, nil
 }
 
 func (c *Cmd) getResourceAndName() (string, string, error) {
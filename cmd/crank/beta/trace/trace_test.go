@@ -1,12 +1,24 @@
 package trace
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/printer"
+	"github.com/crossplane/crossplane/cmd/crank/beta/trace/internal/resource"
 )
 
 func TestCmd_getResourceAndName(t *testing.T) {
@@ -108,3 +120,256 @@ func TestCmd_getResourceAndName(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdPrint(t *testing.T) {
+	root := &resource.Resource{
+		Unstructured: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.org/v1alpha1",
+			"kind":       "XExample",
+			"metadata":   map[string]interface{}{"name": "example"},
+		}},
+	}
+	p, err := printer.New(string(printer.TypeDot))
+	if err != nil {
+		t.Fatalf("printer.New(...): %v", err)
+	}
+	expected := &bytes.Buffer{}
+	if err := p.Print(expected, root); err != nil {
+		t.Fatalf("p.Print(...): %v", err)
+	}
+
+	type args struct {
+		outputFile string
+		existing   string
+	}
+	type want struct {
+		stdout string
+		file   string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Stdout": {
+			reason: "Should print to stdout if no output file is specified",
+			want: want{
+				stdout: expected.String(),
+			},
+		},
+		"OutputFile": {
+			reason: "Should print to the output file, not to stdout, if specified",
+			args: args{
+				outputFile: "graph.dot",
+			},
+			want: want{
+				file: expected.String(),
+			},
+		},
+		"OutputFileTruncated": {
+			reason: "Should truncate the output file if it already exists",
+			args: args{
+				outputFile: "graph.dot",
+				existing:   strings.Repeat("stale\n", 1000),
+			},
+			want: want{
+				file: expected.String(),
+			},
+		},
+		"OutputFileInMissingDirectory": {
+			reason: "Should return an error if the output file cannot be opened",
+			args: args{
+				outputFile: filepath.Join("missing", "graph.dot"),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &Cmd{}
+			if tc.args.outputFile != "" {
+				c.OutputFile = filepath.Join(t.TempDir(), tc.args.outputFile)
+			}
+			if tc.args.existing != "" {
+				if err := os.WriteFile(c.OutputFile, []byte(tc.args.existing), 0o600); err != nil {
+					t.Fatalf("os.WriteFile(...): %v", err)
+				}
+			}
+
+			stdout := &bytes.Buffer{}
+			err := c.print(stdout, p, root)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.print(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.stdout, stdout.String()); diff != "" {
+				t.Errorf("\n%s\nc.print(...): -want stdout, +got stdout:\n%s", tc.reason, diff)
+			}
+			if c.OutputFile == "" || tc.want.err != nil {
+				return
+			}
+			got, err := os.ReadFile(c.OutputFile)
+			if err != nil {
+				t.Fatalf("os.ReadFile(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.file, string(got)); diff != "" {
+				t.Errorf("\n%s\nc.print(...): -want file, +got file:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		// cancelAfter is the number of renders after which the context is
+		// cancelled.
+		cancelAfter int
+		err         error
+	}
+	type want struct {
+		renders int
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CancelledAfterFirstRender": {
+			reason: "Should render once and return without error if interrupted after the first render",
+			args: args{
+				cancelAfter: 1,
+			},
+			want: want{
+				renders: 1,
+			},
+		},
+		"RenderEveryInterval": {
+			reason: "Should render again every interval until interrupted",
+			args: args{
+				cancelAfter: 3,
+			},
+			want: want{
+				renders: 3,
+			},
+		},
+		"RenderError": {
+			reason: "Should stop and return the error if rendering fails",
+			args: args{
+				cancelAfter: 5,
+				err:         errBoom,
+			},
+			want: want{
+				renders: 1,
+				err:     errBoom,
+			},
+		},
+		"RenderInterrupted": {
+			reason: "Should not return an error if rendering fails because it was interrupted",
+			args: args{
+				cancelAfter: 1,
+				err:         context.Canceled,
+			},
+			want: want{
+				renders: 1,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			renders := 0
+			render := func(_ context.Context) error {
+				renders++
+				if renders >= tc.args.cancelAfter {
+					cancel()
+				}
+				return tc.args.err
+			}
+
+			screen := &bytes.Buffer{}
+			err := watch(ctx, screen, time.Millisecond, render)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwatch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.renders, renders); diff != "" {
+				t.Errorf("\n%s\nwatch(...): -want renders, +got renders:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(strings.Repeat(clearScreen, tc.want.renders), screen.String()); diff != "" {
+				t.Errorf("\n%s\nwatch(...): -want screen, +got screen:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCmdClearsScreen(t *testing.T) {
+	type args struct {
+		output     string
+		outputFile string
+		stdout     io.Writer
+	}
+	type want struct {
+		clears bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"JSONOutput": {
+			reason: "Should not clear the screen if printing json, which is meant to be consumed by other programs",
+			args: args{
+				output: "json",
+				stdout: os.Stdout,
+			},
+			want: want{
+				clears: false,
+			},
+		},
+		"DotOutput": {
+			reason: "Should not clear the screen if printing dot, which is meant to be consumed by other programs",
+			args: args{
+				output: "dot",
+				stdout: os.Stdout,
+			},
+			want: want{
+				clears: false,
+			},
+		},
+		"OutputFile": {
+			reason: "Should not clear the screen if the output is written to a file",
+			args: args{
+				output:     "default",
+				outputFile: "trace.txt",
+				stdout:     os.Stdout,
+			},
+			want: want{
+				clears: false,
+			},
+		},
+		"NotATerminal": {
+			reason: "Should not clear the screen if stdout isn't a terminal",
+			args: args{
+				output: "wide",
+				stdout: &bytes.Buffer{},
+			},
+			want: want{
+				clears: false,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &Cmd{Output: tc.args.output, OutputFile: tc.args.outputFile}
+			if diff := cmp.Diff(tc.want.clears, c.clearsScreen(tc.args.stdout)); diff != "" {
+				t.Errorf("\n%s\nclearsScreen(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -88,7 +88,7 @@ func (c *LocalCache) Flush() error {
 
 // Load loads the schemas from the cache directory.
 func (c *LocalCache) Load() ([]*unstructured.Unstructured, error) {
-	loader, err := NewLoader(c.cacheDir)
+	loader, err := NewExtensionLoader(c.cacheDir)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot create loader from the path %s", c.cacheDir)
 	}
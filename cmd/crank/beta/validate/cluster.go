@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/alecthomas/kong"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/pkg/validation/apiextensions/v1/composition"
+)
+
+const (
+	errKubeConfig      = "failed to get kubeconfig"
+	errInitKubeClient  = "cannot init kubeclient"
+	errGetComposition  = "cannot get composition"
+	errFmtGetXRD       = "cannot get composite resource definition %s"
+	errFmtGetCRD       = "cannot get custom resource definition %s"
+	errFmtMapKind      = "cannot find the resource of %s"
+	errConvertCRD      = "cannot convert custom resource definition"
+	errNewValidator    = "cannot create composition validator"
+	errFmtXRDNotFound  = "cannot find composite resource definition for %s"
+	errFmtInvalidComp  = "composition %q is invalid"
+	errKubeAddToScheme = "cannot add kubernetes types to scheme"
+	errExtAddToScheme  = "cannot add apiextensions to scheme"
+	errXPAddToScheme   = "cannot add crossplane apiextensions to scheme"
+	errMissingCompName = "a composition name is required"
+)
+
+// ClusterCmd arguments and flags for the validate-composition subcommand.
+type ClusterCmd struct {
+	// Arguments.
+	Name string `arg:"" help:"Name of the Composition installed in the cluster to validate."`
+
+	// Flags. Keep them in alphabetical order.
+	Context string `default:"" help:"Kubernetes context." name:"context" short:"c"`
+}
+
+// Help prints out the help for the validate-composition command.
+func (c *ClusterCmd) Help() string {
+	return `
+This command validates a Composition installed in a cluster against the schemas
+actually installed there. It fetches the Composition, the composite resource
+definition (XRD) it targets, and the CRDs of all its composed resources, then
+runs the same validation performed by the Composition admission webhook.
+
+Examples:
+
+  # Validate the installed Composition named example against the installed CRDs
+  crossplane beta validate-composition example
+
+  # Validate the Composition using a specific kubeconfig context
+  crossplane beta validate-composition example --context my-cluster
+`
+}
+
+// Run validate-composition.
+func (c *ClusterCmd) Run(k *kong.Context, _ logging.Logger) error {
+	if c.Name == "" {
+		return errors.New(errMissingCompName)
+	}
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: c.Context},
+	).ClientConfig()
+	if err != nil {
+		return errors.Wrap(err, errKubeConfig)
+	}
+
+	// Use a scheme of our own rather than registering types with the global
+	// client-go one.
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		return errors.Wrap(err, errKubeAddToScheme)
+	}
+	if err := extv1.AddToScheme(s); err != nil {
+		return errors.Wrap(err, errExtAddToScheme)
+	}
+	if err := v1.AddToScheme(s); err != nil {
+		return errors.Wrap(err, errXPAddToScheme)
+	}
+
+	kube, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return errors.Wrap(err, errInitKubeClient)
+	}
+
+	return ValidateCompositionFromCluster(context.Background(), kube, kube.RESTMapper(), c.Name, k.Stdout)
+}
+
+// ValidateCompositionFromCluster fetches the named Composition, the XRD it
+// targets and the CRDs of its composed resources using the supplied client,
+// validates the Composition against them and writes the results to w. The
+// supplied RESTMapper is used to find the names of the CRDs and XRDs, so that
+// only the ones the Composition needs are fetched. It returns an error if the
+// Composition is invalid.
+func ValidateCompositionFromCluster(ctx context.Context, c client.Reader, m meta.RESTMapper, name string, w io.Writer) error {
+	comp := &v1.Composition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, comp); err != nil {
+		return errors.Wrap(err, errGetComposition)
+	}
+
+	xrGK := schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind).GroupKind()
+	xrd, err := getXRD(ctx, c, m, xrGK)
+	if err != nil {
+		return err
+	}
+	if xrd == nil {
+		return errors.Errorf(errFmtXRDNotFound, xrGK)
+	}
+
+	crds, xrds, err := getComposedCRDs(ctx, c, m, comp)
+	if err != nil {
+		return err
+	}
+
+	v, err := composition.NewValidator(composition.WithCRDGetterFromMap(crds), composition.WithCompositeResourceDefinitions(append(xrds, xrd)...))
+	if err != nil {
+		return errors.Wrap(err, errNewValidator)
+	}
+
+	warns, errs := v.Validate(ctx, comp)
+	if err := printCompositionResults(w, comp.GetName(), warns, errs); err != nil {
+		return err
+	}
+	if len(errs) != 0 {
+		return errors.Errorf(errFmtInvalidComp, comp.GetName())
+	}
+	return nil
+}
+
+// getComposedCRDs returns the installed CRDs of the resources composed by the
+// supplied Composition, including the ones embedded in the input of
+// function-patch-and-transform pipeline steps, indexed by GroupKind. It also
+// returns the XRDs of the composed resources that are themselves composite
+// resources. CRDs that aren't installed are left to the validator to report.
+func getComposedCRDs(ctx context.Context, c client.Reader, m meta.RESTMapper, comp *v1.Composition) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, []*v1.CompositeResourceDefinition, error) {
+	resources := append(slices.Clone(comp.Spec.Resources), composition.PatchAndTransformInputResources(comp)...)
+
+	crds := make([]*extv1.CustomResourceDefinition, 0, len(resources))
+	xrds := make([]*v1.CompositeResourceDefinition, 0)
+	seen := map[schema.GroupKind]bool{}
+	for i := range resources {
+		gvk, err := composition.GetBaseObjectGVK(&resources[i])
+		if err != nil {
+			// Invalid bases are reported by the validator.
+			continue
+		}
+		gk := gvk.GroupKind()
+		if seen[gk] {
+			continue
+		}
+		seen[gk] = true
+
+		crd, err := getCRD(ctx, c, m, gk)
+		if err != nil {
+			return nil, nil, err
+		}
+		if crd == nil {
+			continue
+		}
+		crds = append(crds, crd)
+
+		// Composite resources are validated against the schema derived
+		// from their XRD, which owns their CRD.
+		if ref := metav1.GetControllerOf(crd); ref == nil || ref.Kind != v1.CompositeResourceDefinitionKind {
+			continue
+		}
+		xrd, err := getXRD(ctx, c, m, gk)
+		if err != nil {
+			return nil, nil, err
+		}
+		if xrd != nil {
+			xrds = append(xrds, xrd)
+		}
+	}
+
+	out, err := toCRDMap(crds)
+	return out, xrds, err
+}
+
+// getCRD returns the installed CRD of the supplied kind, or nil if there's
+// none.
+func getCRD(ctx context.Context, c client.Reader, m meta.RESTMapper, gk schema.GroupKind) (*extv1.CustomResourceDefinition, error) {
+	name, err := resourceName(m, gk)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	crd := &extv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, errFmtGetCRD, name)
+	}
+	return crd, nil
+}
+
+// getXRD returns the installed XRD defining the supplied composite resource
+// kind, or nil if there's none.
+func getXRD(ctx context.Context, c client.Reader, m meta.RESTMapper, gk schema.GroupKind) (*v1.CompositeResourceDefinition, error) {
+	name, err := resourceName(m, gk)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	xrd := &v1.CompositeResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, xrd); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, errFmtGetXRD, name)
+	}
+	return xrd, nil
+}
+
+// resourceName returns the name of the CRD, or XRD, defining the supplied
+// kind, i.e. <plural>.<group>. It returns an empty name if the kind isn't
+// served by the API server.
+func resourceName(m meta.RESTMapper, gk schema.GroupKind) (string, error) {
+	mapping, err := m.RESTMapping(gk)
+	if meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtMapKind, gk)
+	}
+	return mapping.Resource.GroupResource().String(), nil
+}
+
+// printCompositionResults prints the supplied warnings and errors of the named
+// Composition. It only returns errors writing them.
+func printCompositionResults(w io.Writer, name string, warns []string, errs field.ErrorList) error {
+	for _, warn := range warns {
+		if _, err := fmt.Fprintf(w, "[!] %s\n", warn); err != nil {
+			return errors.Wrap(err, errWriteOutput)
+		}
+	}
+	for _, e := range errs {
+		if _, err := fmt.Fprintf(w, "[x] %s\n", e.Error()); err != nil {
+			return errors.Wrap(err, errWriteOutput)
+		}
+	}
+	if len(errs) != 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "[✓] composition %q validated successfully\n", name); err != nil {
+		return errors.Wrap(err, errWriteOutput)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestValidateCompositionFromCluster(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "xtests.example.org"},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group: "example.org",
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:     "XTest",
+				ListKind: "XTestList",
+				Plural:   "xtests",
+				Singular: "xtest",
+			},
+			Versions: []v1.CompositeResourceDefinitionVersion{{
+				Name:          "v1alpha1",
+				Served:        true,
+				Referenceable: true,
+				Schema: &v1.CompositeResourceValidation{
+					OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(`{"type":"object","properties":{"spec":{"type":"object","properties":{"replicas":{"type":"integer"}}}}}`)},
+				},
+			}},
+		},
+	}
+
+	comp := func(toFieldPath string) *v1.Composition {
+		return &v1.Composition{
+			ObjectMeta: metav1.ObjectMeta{Name: "example"},
+			Spec: v1.CompositionSpec{
+				CompositeTypeRef: v1.TypeReference{
+					APIVersion: "example.org/v1alpha1",
+					Kind:       "XTest",
+				},
+				Resources: []v1.ComposedTemplate{{
+					Name: ptr.To("test"),
+					Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"test.org/v1alpha1","kind":"Test","spec":{"replicas":1}}`)},
+					Patches: []v1.Patch{{
+						Type:          v1.PatchTypeFromCompositeFieldPath,
+						FromFieldPath: ptr.To("spec.replicas"),
+						ToFieldPath:   ptr.To(toFieldPath),
+					}},
+				}},
+			},
+		}
+	}
+
+	// kube serves the supplied Composition and XRDs, and the test CRD, by
+	// name. It doesn't support lists, only the needed CRDs and XRDs should
+	// be fetched.
+	kube := func(c *v1.Composition, xrds ...v1.CompositeResourceDefinition) client.Reader {
+		return &test.MockClient{
+			MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				switch o := obj.(type) {
+				case *v1.Composition:
+					c.DeepCopyInto(o)
+					return nil
+				case *extv1.CustomResourceDefinition:
+					if key.Name == "tests.test.org" {
+						testCRD.DeepCopyInto(o)
+						return nil
+					}
+				case *v1.CompositeResourceDefinition:
+					for i := range xrds {
+						if key.Name == xrds[i].GetName() {
+							xrds[i].DeepCopyInto(o)
+							return nil
+						}
+					}
+				}
+				return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+			},
+		}
+	}
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "example.org", Version: "v1alpha1"}, {Group: "test.org", Version: "v1alpha1"}})
+	mapper.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XTest"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "test.org", Version: "v1alpha1", Kind: "Test"}, meta.RESTScopeRoot)
+
+	type args struct {
+		client client.Reader
+		name   string
+		w      io.Writer
+	}
+	type want struct {
+		output string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should successfully validate a Composition against the installed XRD and CRDs.",
+			args: args{
+				client: kube(comp("spec.replicas"), xrd),
+				name:   "example",
+			},
+			want: want{
+				output: "[✓] composition \"example\" validated successfully\n",
+			},
+		},
+		"InvalidPatch": {
+			reason: "Should return an error if a patch targets a field not in the installed CRD.",
+			args: args{
+				client: kube(comp("spec.doesNotExist"), xrd),
+				name:   "example",
+			},
+			want: want{
+				output: "[x]",
+				err:    cmpopts.AnyError,
+			},
+		},
+		"XRDNotFound": {
+			reason: "Should return an error if the XRD referenced by the Composition is not installed.",
+			args: args{
+				client: kube(comp("spec.replicas")),
+				name:   "example",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"GetCRDError": {
+			reason: "Should return an error if a needed CRD cannot be fetched.",
+			args: args{
+				client: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1.Composition:
+						comp("spec.replicas").DeepCopyInto(o)
+						return nil
+					case *v1.CompositeResourceDefinition:
+						xrd.DeepCopyInto(o)
+						return nil
+					}
+					return errBoom
+				}},
+				name: "example",
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"WriteError": {
+			reason: "Should return an error if the results cannot be written.",
+			args: args{
+				client: kube(comp("spec.doesNotExist"), xrd),
+				name:   "example",
+				w:      failingWriter{err: errBoom},
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"GetCompositionError": {
+			reason: "Should return an error if the Composition cannot be fetched.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				name:   "example",
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var w io.Writer = buf
+			if tc.args.w != nil {
+				w = tc.args.w
+			}
+			err := ValidateCompositionFromCluster(context.Background(), tc.args.client, mapper, tc.args.name, w)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nValidateCompositionFromCluster(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if !strings.HasPrefix(buf.String(), tc.want.output) {
+				t.Errorf("%s\nValidateCompositionFromCluster(...): want output starting with %q, got %q", tc.reason, tc.want.output, buf.String())
+			}
+		})
+	}
+}
+
+// failingWriter fails all writes with the supplied error.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(_ []byte) (int, error) {
+	return 0, w.err
+}
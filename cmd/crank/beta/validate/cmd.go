@@ -24,9 +24,12 @@ import (
 
 	"github.com/alecthomas/kong"
 	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
 )
 
 // Cmd arguments and flags for render subcommand.
@@ -36,8 +39,12 @@ type Cmd struct {
 	Resources  string `arg:"" help:"Resources source which can be a file, directory, or '-' for standard input."`
 
 	// Flags. Keep them in alphabetical order.
-	CacheDir           string `default:".crossplane/cache"                                          help:"Absolute path to the cache directory where downloaded schemas are stored."`
+	CacheDir           string `default:".crossplane/cache"                                                                                     help:"Absolute path to the cache directory where downloaded schemas are stored."`
 	CleanCache         bool   `help:"Clean the cache directory before downloading package schemas."`
+	DumpRendered       bool   `help:"Print resources failing validation as YAML, after their validation errors."`
+	Explain            bool   `help:"Explain common validation errors, e.g. type mismatches, and suggest how to fix them."`
+	GroupByResource    bool   `help:"Print all the validation results of a resource together, sorting resources by GroupVersionKind and name."`
+	Output             string `default:"default"                                                                                               enum:"default,json,yaml,junit"                                                   help:"Output format of the validation results. One of: default, json, yaml, junit." short:"o"`
 	SkipSuccessResults bool   `help:"Skip printing success results."`
 
 	fs afero.Fs
@@ -51,7 +58,14 @@ CRDs, providers, and configurations. The output of the "crossplane beta render"
 piped to this validate command in order to rapidly validate on the outputs of the composition development experience.
 
 If providers or configurations are provided as extensions, they will be downloaded and loaded as CRDs before performing
-validation. If the cache directory is not provided, it will default to ".crossplane/cache" in the current workspace. 
+validation. Directories containing an unpacked package, i.e. a package.yaml file, are loaded as packages: only the
+package.yaml file and the manifests in the crds directory are loaded. If the resources are an unpacked package, e.g. a
+Configuration, the XRDs and CRDs it ships are loaded as extensions instead.
+
+Compositions among the resources are validated as the Composition admission webhook would, checking their patches,
+readiness checks and connection details against the schemas of the composite and composed resources.
+
+If the cache directory is not provided, it will default to ".crossplane/cache" in the current workspace. 
 Cache directory can be cleaned before downloading schemas by setting the "clean-cache" flag.
 
 All validation is performed offline locally using the Kubernetes API server's validation library, so it does not require 
@@ -69,6 +83,28 @@ Examples:
   # Validate the output of the render command against the extensions in the extensionsDir folder
   crossplane beta render xr.yaml composition.yaml func.yaml --include-full-xr | crossplane beta validate extensionsDir/ -
 
+  # Validate the output of the render command and print the rendered resources failing validation
+  crossplane beta render xr.yaml composition.yaml func.yaml | crossplane beta validate extensionsDir/ - --dump-rendered
+
+  # Validate a Composition offline against the CRDs of an unpacked provider package
+  crossplane beta validate provider-nop/ composition.yaml
+
+  # Validate the Compositions of an unpacked Configuration package against the XRDs it ships and the CRDs of an
+  # unpacked provider package
+  crossplane beta validate provider-nop/ configuration-nop/
+
+  # Validate all resources in the resourceDir folder and print the results grouped by resource
+  crossplane beta validate extensionsDir/ resourceDir/ --group-by-resource
+
+  # Validate all resources in the resourceDir folder and print the results as YAML
+  crossplane beta validate extensionsDir/ resourceDir/ -o yaml
+
+  # Validate all resources in the resourceDir folder and write a JUnit report for CI
+  crossplane beta validate extensionsDir/ resourceDir/ -o junit > report.xml
+
+  # Validate all resources in the resourceDir folder, explaining common validation errors
+  crossplane beta validate extensionsDir/ resourceDir/ --explain
+
   # Validate all resources in the resourceDir folder against the extensions in the extensionsDir folder using provided
   # cache directory and clean the cache directory before downloading schemas
   crossplane beta validate extensionsDir/ resourceDir/ --cache-dir .cache --clean-cache
@@ -88,7 +124,7 @@ func (c *Cmd) Run(k *kong.Context, _ logging.Logger) error {
 	}
 
 	// Load all extensions
-	extensionLoader, err := NewLoader(c.Extensions)
+	extensionLoader, err := NewExtensionLoader(c.Extensions)
 	if err != nil {
 		return errors.Wrapf(err, "cannot load extensions from %q", c.Extensions)
 	}
@@ -109,6 +145,15 @@ func (c *Cmd) Run(k *kong.Context, _ logging.Logger) error {
 		return errors.Wrapf(err, "cannot load resources from %q", c.Resources)
 	}
 
+	// The XRDs and CRDs shipped by an unpacked package, e.g. a Configuration,
+	// are extensions rather than resources, so that its Compositions are
+	// validated against them.
+	if isPackageDir(c.Resources) {
+		var pkgExtensions []*unstructured.Unstructured
+		pkgExtensions, resources = manifest.SplitExtensions(resources)
+		extensions = append(extensions, pkgExtensions...)
+	}
+
 	// Update default cache directory to absolute path based on the current working directory
 	if c.CacheDir == defaultCacheDir {
 		currentPath, err := os.Getwd()
@@ -123,7 +168,12 @@ func (c *Cmd) Run(k *kong.Context, _ logging.Logger) error {
 		c.CacheDir = filepath.Join(homeDir, c.CacheDir[2:])
 	}
 
-	m := NewManager(c.CacheDir, c.fs, k.Stdout)
+	// Keep standard output for the results when they're structured.
+	logs := k.Stdout
+	if c.Output != OutputDefault {
+		logs = k.Stderr
+	}
+	m := NewManager(c.CacheDir, c.fs, logs)
 
 	// Convert XRDs/CRDs to CRDs and add package dependencies
 	if err := m.PrepExtensions(extensions); err != nil {
@@ -135,8 +185,19 @@ func (c *Cmd) Run(k *kong.Context, _ logging.Logger) error {
 		return errors.Wrapf(err, "cannot download and load cache")
 	}
 
-	// Validate resources against schemas
-	if err := SchemaValidation(resources, m.crds, c.SkipSuccessResults, k.Stdout); err != nil {
+	opts := Options{
+		SkipSuccessLogs: c.SkipSuccessResults,
+		DumpRendered:    c.DumpRendered,
+		GroupByResource: c.GroupByResource,
+		Explain:         c.Explain,
+	}
+	if c.Output != OutputDefault {
+		return errors.Wrap(StructuredValidation(resources, m.crds, opts, c.Output, k.Stdout), "cannot validate resources")
+	}
+
+	// Validate resources against schemas, and Compositions as the admission
+	// webhook would
+	if err := SchemaValidation(resources, m.crds, opts, k.Stdout); err != nil {
 		return errors.Wrapf(err, "cannot validate resources")
 	}
 
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/pkg/validation/apiextensions/v1/composition"
+)
+
+const (
+	errConvertComposition = "cannot convert composition"
+)
+
+// isComposition returns true if the supplied resource is a Composition.
+func isComposition(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind() == v1.CompositionGroupVersionKind
+}
+
+// validateCompositions validates the supplied Compositions against the
+// supplied CRDs, performing the same validation as the Composition admission
+// webhook, and returns a result for each of them. CRDs derived from XRDs are
+// used for both composite resources and nested composite resources. Like the
+// webhook, Compositions whose composite or composed resources have no CRD are
+// only validated on their own, and reported as missing schemas if valid.
+func validateCompositions(comps []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition) ([]resourceResult, error) {
+	m, err := toCRDMap(crds)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := composition.NewValidator(composition.WithCRDGetterFromMap(m))
+	if err != nil {
+		return nil, errors.Wrap(err, errNewValidator)
+	}
+
+	results := make([]resourceResult, 0, len(comps))
+	for _, u := range comps {
+		comp := &v1.Composition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, comp); err != nil {
+			return nil, errors.Wrap(err, errConvertComposition)
+		}
+		res := resourceResult{resource: u}
+
+		var warns []string
+		var errs field.ErrorList
+		if missing := getMissingSchemas(comp, m); len(missing) != 0 {
+			warns, errs = comp.Validate()
+			if len(errs) == 0 {
+				res.missing = missing
+			}
+		} else {
+			warns, errs = v.Validate(context.Background(), comp)
+		}
+
+		res.warns = warns
+		for _, e := range errs {
+			res.errs = append(res.errs, validationError{kind: "composition", err: e})
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// getMissingSchemas returns the GroupVersionKinds of the composite and
+// composed resources of the supplied Composition, including the ones embedded
+// in the input of function-patch-and-transform pipeline steps, whose CRD is
+// not among the supplied ones.
+func getMissingSchemas(comp *v1.Composition, crds map[schema.GroupKind]apiextensions.CustomResourceDefinition) []schema.GroupVersionKind {
+	gvks := []schema.GroupVersionKind{schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)}
+	resources := append(slices.Clone(comp.Spec.Resources), composition.PatchAndTransformInputResources(comp)...)
+	for i := range resources {
+		gvk, err := composition.GetBaseObjectGVK(&resources[i])
+		if err != nil {
+			// Invalid bases are reported by the validator.
+			continue
+		}
+		gvks = append(gvks, gvk)
+	}
+
+	var missing []schema.GroupVersionKind
+	for _, gvk := range gvks {
+		if _, ok := crds[gvk.GroupKind()]; ok || slices.Contains(missing, gvk) {
+			continue
+		}
+		missing = append(missing, gvk)
+	}
+	return missing
+}
+
+// toCRDMap converts the supplied CRDs to the internal version, indexed by
+// GroupKind, as expected by the Composition validator.
+func toCRDMap(crds []*extv1.CustomResourceDefinition) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, error) {
+	m := make(map[schema.GroupKind]apiextensions.CustomResourceDefinition, len(crds))
+	for _, crd := range crds {
+		internal := apiextensions.CustomResourceDefinition{}
+		if err := extv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(crd, &internal, nil); err != nil {
+			return nil, errors.Wrap(err, errConvertCRD)
+		}
+		m[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = internal
+	}
+	return m, nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
+)
+
+func TestCompositionValidation(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	comp := func(toFieldPath string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.crossplane.io/v1",
+			"kind":       "Composition",
+			"metadata": map[string]interface{}{
+				"name": "example",
+			},
+			"spec": map[string]interface{}{
+				"compositeTypeRef": map[string]interface{}{
+					"apiVersion": "test.org/v1alpha1",
+					"kind":       "Test",
+				},
+				"resources": []interface{}{
+					map[string]interface{}{
+						"name": "test",
+						"base": map[string]interface{}{
+							"apiVersion": "test.org/v1alpha1",
+							"kind":       "Test",
+							"spec": map[string]interface{}{
+								"replicas": 1,
+							},
+						},
+						"patches": []interface{}{
+							map[string]interface{}{
+								"type":          "FromCompositeFieldPath",
+								"fromFieldPath": "spec.replicas",
+								"toFieldPath":   toFieldPath,
+							},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	test := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "test.org/v1alpha1",
+		"kind":       "Test",
+		"metadata": map[string]interface{}{
+			"name": "test",
+		},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+		},
+	}}
+
+	type args struct {
+		resources []*unstructured.Unstructured
+		crds      []*extv1.CustomResourceDefinition
+		opts      Options
+		w         io.Writer
+	}
+	type want struct {
+		output string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should successfully validate a Composition against the supplied CRDs.",
+			args: args{
+				resources: []*unstructured.Unstructured{comp("spec.replicas")},
+				crds:      []*extv1.CustomResourceDefinition{testCRD},
+			},
+			want: want{
+				output: "[✓] apiextensions.crossplane.io/v1, Kind=Composition, example validated successfully\n" +
+					"Total 1 resources: 0 missing schemas, 1 success cases, 0 failure cases\n",
+			},
+		},
+		"SkipSuccessLogs": {
+			reason: "Should not print a valid Composition if success results are skipped.",
+			args: args{
+				resources: []*unstructured.Unstructured{comp("spec.replicas")},
+				crds:      []*extv1.CustomResourceDefinition{testCRD},
+				opts:      Options{SkipSuccessLogs: true},
+			},
+			want: want{
+				output: "Total 1 resources: 0 missing schemas, 1 success cases, 0 failure cases\n",
+			},
+		},
+		"InvalidPatch": {
+			reason: "Should return an error if a patch targets a field not in the supplied CRDs, counting the Composition as a failure.",
+			args: args{
+				resources: []*unstructured.Unstructured{comp("spec.doesNotExist"), test},
+				crds:      []*extv1.CustomResourceDefinition{testCRD},
+			},
+			want: want{
+				output: "[x] composition validation error apiextensions.crossplane.io/v1, Kind=Composition, example : spec.resources[0].patches[0].toFieldPath: Invalid value: \"spec.doesNotExist\": field 'doesNotExist' is not valid according to the schema\n" +
+					"[✓] test.org/v1alpha1, Kind=Test, test validated successfully\n" +
+					"Total 2 resources: 0 missing schemas, 1 success cases, 1 failure cases\n",
+				err: cmpopts.AnyError,
+			},
+		},
+		"MissingSchema": {
+			reason: "Should report the missing CRDs of the composite and composed resources of a Composition as missing schemas.",
+			args: args{
+				resources: []*unstructured.Unstructured{comp("spec.replicas")},
+			},
+			want: want{
+				output: "[!] could not find CRD/XRD for: test.org/v1alpha1, Kind=Test\n" +
+					"Total 1 resources: 1 missing schemas, 0 success cases, 0 failure cases\n",
+			},
+		},
+		"WriteError": {
+			reason: "Should return the error writing the results of an invalid Composition.",
+			args: args{
+				resources: []*unstructured.Unstructured{comp("spec.doesNotExist")},
+				crds:      []*extv1.CustomResourceDefinition{testCRD},
+				w:         failingWriter{err: errBoom},
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var w io.Writer = buf
+			if tc.args.w != nil {
+				w = tc.args.w
+			}
+			err := SchemaValidation(tc.args.resources, tc.args.crds, tc.args.opts, w)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nSchemaValidation(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.output, buf.String()); diff != "" {
+				t.Errorf("%s\nSchemaValidation(...): -want output, +got output:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionValidationFromPackage(t *testing.T) {
+	type args struct {
+		comp string
+	}
+	type want struct {
+		output string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should validate a Composition of a Configuration package against the XRDs it ships and the supplied provider CRDs.",
+			args: args{
+				comp: "xcoolresources.example.org",
+			},
+			want: want{
+				output: "[✓] apiextensions.crossplane.io/v1, Kind=Composition, xcoolresources.example.org validated successfully\n",
+			},
+		},
+		"InvalidPatch": {
+			reason: "Should return an error if a patch of a Composition of a Configuration package reads a field not in the XRDs it ships.",
+			args: args{
+				comp: "xcoolresources-broken.example.org",
+			},
+			want: want{
+				output: "[x]",
+				err:    cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			extensions, err := (&PackageLoader{path: "testdata/package"}).Load()
+			if err != nil {
+				t.Fatalf("Load(...): unexpected error: %v", err)
+			}
+			resources, err := (&PackageLoader{path: "testdata/configuration"}).Load()
+			if err != nil {
+				t.Fatalf("Load(...): unexpected error: %v", err)
+			}
+			pkgExtensions, _ := manifest.SplitExtensions(resources)
+			m := NewManager(defaultCacheDir, afero.NewMemMapFs(), &bytes.Buffer{})
+			if err := m.PrepExtensions(append(extensions, pkgExtensions...)); err != nil {
+				t.Fatalf("PrepExtensions(...): unexpected error: %v", err)
+			}
+			var comps []*unstructured.Unstructured
+			for _, r := range resources {
+				if isComposition(r) && r.GetName() == tc.args.comp {
+					comps = append(comps, r)
+				}
+			}
+
+			w := &bytes.Buffer{}
+			err = SchemaValidation(comps, m.crds, Options{}, w)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nSchemaValidation(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if !strings.HasPrefix(w.String(), tc.want.output) {
+				t.Errorf("%s\nSchemaValidation(...): want output starting with %q, got %q", tc.reason, tc.want.output, w.String())
+			}
+		})
+	}
+}
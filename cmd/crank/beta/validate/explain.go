@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// An explanation explains a common category of validation errors and
+// suggests how to fix them.
+type explanation struct {
+	// matches returns true if the supplied error belongs to the category.
+	matches func(e *field.Error) bool
+
+	// text is the explanation and suggestion appended to matching errors.
+	text string
+}
+
+// explanations are evaluated in order, only the first matching one is
+// appended to an error.
+var explanations = []explanation{
+	{
+		// Type mismatches are reported both validating resources against
+		// their schema and validating patches against the schemas of the
+		// fields they read from and write to.
+		matches: func(e *field.Error) bool {
+			return e.Type == field.ErrorTypeTypeInvalid ||
+				strings.Contains(e.Detail, "must be of type") ||
+				strings.Contains(e.Detail, "compatible with the toFieldPath")
+		},
+		text: "the value doesn't have the type the schema expects; fix the value, or convert it using a transform if it's patched",
+	},
+	{
+		matches: func(e *field.Error) bool {
+			return e.Type == field.ErrorTypeNotFound ||
+				strings.Contains(e.Detail, "is not valid according to the schema")
+		},
+		text: "the field doesn't exist in the schema; check the field path for typos and that it's using the expected version of the resource",
+	},
+	{
+		matches: func(e *field.Error) bool {
+			return e.Type == field.ErrorTypeRequired
+		},
+		text: "the schema requires the field, but it's not guaranteed to be set; set it, or patch it from a field the schema requires too",
+	},
+}
+
+// explain returns a copy of the supplied error, with the explanation of its
+// category appended to its detail, if it belongs to a known category.
+func explain(e *field.Error) *field.Error {
+	for _, x := range explanations {
+		if !x.matches(e) {
+			continue
+		}
+		out := *e
+		if out.Detail != "" {
+			out.Detail += "; "
+		}
+		out.Detail += "hint: " + x.text
+		return &out
+	}
+	return e
+}
+
+// explainAll returns the supplied errors, with the explanation of their
+// category appended to those belonging to a known category.
+func explainAll(errs field.ErrorList) field.ErrorList {
+	out := make(field.ErrorList, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, explain(e))
+	}
+	return out
+}
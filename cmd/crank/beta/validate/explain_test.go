@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestExplainAll(t *testing.T) {
+	type args struct {
+		errs field.ErrorList
+	}
+	type want struct {
+		errs field.ErrorList
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"TypeMismatchSchema": {
+			reason: "Should explain a value not matching the type required by the schema",
+			args: args{
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "replicas"), "3", "spec.replicas in body must be of type integer: \"string\""),
+				},
+			},
+			want: want{
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "replicas"), "3", "spec.replicas in body must be of type integer: \"string\"; hint: "+explanations[0].text),
+				},
+			},
+		},
+		"TypeMismatchPatch": {
+			reason: "Should explain a patch writing a value of the wrong type",
+			args: args{
+				errs: field.ErrorList{
+					field.Required(field.NewPath("spec", "resources").Index(0).Child("patches").Index(0).Child("transforms"), "the fromFieldPath does not have a type compatible with the toFieldPath according to their schemas and no transforms were provided: string != integer"),
+				},
+			},
+			want: want{
+				errs: field.ErrorList{
+					field.Required(field.NewPath("spec", "resources").Index(0).Child("patches").Index(0).Child("transforms"), "the fromFieldPath does not have a type compatible with the toFieldPath according to their schemas and no transforms were provided: string != integer; hint: "+explanations[0].text),
+				},
+			},
+		},
+		"MissingField": {
+			reason: "Should explain a field path not existing in the schema",
+			args: args{
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resources").Index(0).Child("patches").Index(0).Child("toFieldPath"), "spec.sise", "field 'sise' is not valid according to the schema"),
+				},
+			},
+			want: want{
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resources").Index(0).Child("patches").Index(0).Child("toFieldPath"), "spec.sise", "field 'sise' is not valid according to the schema; hint: "+explanations[1].text),
+				},
+			},
+		},
+		"RequiredField": {
+			reason: "Should explain a required field not guaranteed to be set",
+			args: args{
+				errs: field.ErrorList{
+					field.Required(field.NewPath("spec", "size"), ""),
+				},
+			},
+			want: want{
+				errs: field.ErrorList{
+					field.Required(field.NewPath("spec", "size"), "hint: "+explanations[2].text),
+				},
+			},
+		},
+		"UnknownCategory": {
+			reason: "Should leave errors not belonging to a known category untouched",
+			args: args{
+				errs: field.ErrorList{
+					field.Duplicate(field.NewPath("spec", "resources").Index(1).Child("name"), "test"),
+				},
+			},
+			want: want{
+				errs: field.ErrorList{
+					field.Duplicate(field.NewPath("spec", "resources").Index(1).Child("name"), "test"),
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := explainAll(tc.args.errs)
+			if diff := cmp.Diff(tc.want.errs, got); diff != "" {
+				t.Errorf("%s\nexplainAll(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
 )
 
 // ImageFetcher defines an interface for fetching images.
@@ -80,7 +82,7 @@ func extractPackageContent(layer conregv1.Layer) ([][]byte, []byte, error) {
 		return nil, nil, errors.Wrapf(err, "cannot get uncompressed layer")
 	}
 
-	objs, err := load(rc)
+	objs, err := manifest.Split(rc)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "cannot read from layer")
 	}
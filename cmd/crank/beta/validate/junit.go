@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuiteName is the name of the JUnit test suite validation results
+// are reported as.
+const junitTestSuiteName = "crossplane-beta-validate"
+
+// JUnitTestSuites is the root element of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// A JUnitTestSuite groups the JUnit test cases of a single run.
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// A JUnitTestCase is the result of validating a single resource.
+type JUnitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped  `xml:"skipped,omitempty"`
+}
+
+// A JUnitFailure is a validation error found validating a resource.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a resource that could not be validated.
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// toJUnit converts the supplied results to a JUnit report, where each
+// resource is a test case and each of its validation errors a failure.
+// Resources whose schema is missing are skipped.
+func toJUnit(in Results) JUnitTestSuites {
+	suite := JUnitTestSuite{
+		Name:      junitTestSuiteName,
+		Tests:     in.Summary.Total,
+		Failures:  in.Summary.Failure,
+		Skipped:   in.Summary.MissingSchemas,
+		TestCases: make([]JUnitTestCase, 0, len(in.Resources)),
+	}
+	for _, r := range in.Resources {
+		tc := JUnitTestCase{
+			Name:      fmt.Sprintf("%s/%s", r.Kind, r.Name),
+			ClassName: r.APIVersion,
+		}
+		if r.Status == ResultStatusMissingSchema {
+			tc.Skipped = &JUnitSkipped{Message: fmt.Sprintf("could not find CRD/XRD for: %s", strings.Join(r.MissingSchemas, ", "))}
+		}
+		for _, e := range r.Errors {
+			f := JUnitFailure{Message: e.Message, Type: e.Type, Content: e.Message}
+			if e.Field != "" {
+				f.Content = fmt.Sprintf("%s: %s", e.Field, e.Message)
+			}
+			tc.Failures = append(tc.Failures, f)
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return JUnitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []JUnitTestSuite{suite},
+	}
+}
+
+// marshalJUnit marshals the supplied JUnit report to indented XML, including
+// the XML header.
+func marshalJUnit(v any) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
@@ -17,15 +17,18 @@ limitations under the License.
 package validate
 
 import (
-	"bufio"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
 )
 
 // Loader interface defines the contract for different input sources.
@@ -45,23 +48,40 @@ func NewLoader(input string) (Loader, error) {
 	}
 
 	if fi.IsDir() {
+		if isPackageDir(input) {
+			return &PackageLoader{path: input}, nil
+		}
 		return &FolderLoader{path: input}, nil
 	}
 
 	return &FileLoader{path: input}, nil
 }
 
+// NewExtensionLoader returns a Loader for extensions, i.e. CRDs, XRDs and the
+// packages shipping them, based on the input source. Unlike NewLoader, folders
+// may contain documents that aren't Kubernetes objects, e.g. Helm values files
+// shipped along CRDs, which are skipped.
+func NewExtensionLoader(input string) (Loader, error) {
+	l, err := NewLoader(input)
+	if fl, ok := l.(*FolderLoader); ok {
+		fl.skipNonObjects = true
+	}
+	return l, err
+}
+
 // StdinLoader implements the Loader interface for reading from stdin.
-type StdinLoader struct{}
+type StdinLoader struct {
+	// in is read instead of stdin if set.
+	in io.Reader
+}
 
 // Load reads the contents from stdin.
 func (s *StdinLoader) Load() ([]*unstructured.Unstructured, error) {
-	stream, err := load(os.Stdin)
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot load YAML stream from stdin")
+	var in io.Reader = os.Stdin
+	if s.in != nil {
+		in = s.in
 	}
-
-	return streamToUnstructured(stream)
+	return manifest.Decode("stdin", in)
 }
 
 // FileLoader implements the Loader interface for reading from a file and converting input to unstructured objects.
@@ -76,84 +96,134 @@ func (f *FileLoader) Load() ([]*unstructured.Unstructured, error) {
 		return nil, errors.Wrap(err, "cannot read file")
 	}
 
-	return streamToUnstructured(stream)
+	return manifest.Unmarshal(f.path, stream)
 }
 
 // FolderLoader implements the Loader interface for reading from a folder.
 type FolderLoader struct {
 	path string
+
+	// skipNonObjects skips documents that aren't Kubernetes objects, rather
+	// than returning an error.
+	skipNonObjects bool
 }
 
-// Load reads the contents from all files in a folder.
+// Load reads the contents from all files in a folder and its subfolders.
+// Symlinks to files are loaded, symlinks to folders are not followed.
 func (f *FolderLoader) Load() ([]*unstructured.Unstructured, error) {
-	var stream [][]byte
-	err := filepath.Walk(f.path, func(path string, info os.FileInfo, err error) error {
+	// Resolve the folder itself if it's a symlink, as it wouldn't be walked.
+	root, err := filepath.EvalSymlinks(f.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read folder")
+	}
+
+	var us []*unstructured.Unstructured
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if isYamlFile(info) {
-			s, err := readFile(path)
-			if err != nil {
-				return err
+		if info.Mode()&os.ModeSymlink != 0 {
+			// Walk doesn't follow symlinks, so resolving the ones to files
+			// can't introduce loops, while the ones to folders are skipped
+			// as they are not manifest files.
+			if info, err = os.Stat(path); err != nil {
+				return errors.Wrapf(err, "cannot resolve symlink %q", path)
 			}
+		}
+		if !isManifestFile(info) {
+			return nil
+		}
+		s, err := readFile(path)
+		if err != nil {
+			return err
+		}
 
-			stream = append(stream, s...)
+		if f.skipNonObjects {
+			s = slices.DeleteFunc(s, func(m []byte) bool { return !isObjectManifest(m) })
 		}
+		fus, err := manifest.Unmarshal(path, s)
+		if err != nil {
+			return err
+		}
+		us = append(us, fus...)
 		return nil
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot read folder")
 	}
 
-	return streamToUnstructured(stream)
+	return us, nil
 }
 
-func isYamlFile(info os.FileInfo) bool {
-	return !info.IsDir() && (filepath.Ext(info.Name()) == ".yaml" || filepath.Ext(info.Name()) == ".yml")
+// PackageLoader implements the Loader interface for reading the CRDs, and any
+// other object, shipped by an unpacked package, e.g. the base layer of a
+// provider package. It reads the package.yaml stream at the root of the
+// package and all the manifests in its crds folder, ignoring examples and any
+// other file shipped along the package.
+type PackageLoader struct {
+	path string
 }
 
-func readFile(path string) ([][]byte, error) {
-	f, err := os.Open(filepath.Clean(path))
+// Load reads the contents of the package.
+func (p *PackageLoader) Load() ([]*unstructured.Unstructured, error) {
+	file := filepath.Join(p.path, packageFileName)
+	stream, err := readFile(file)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot open file")
+		return nil, errors.Wrap(err, "cannot read package file")
+	}
+	ps, err := manifest.Unmarshal(file, stream)
+	if err != nil {
+		return nil, err
 	}
-	defer f.Close() //nolint:errcheck // Only open for reading.
-
-	return load(f)
-}
-
-func load(r io.Reader) ([][]byte, error) {
-	stream := make([][]byte, 0)
-
-	yr := yaml.NewYAMLReader(bufio.NewReader(r))
 
-	for {
-		bytes, err := yr.Read()
-		if errors.Is(err, io.EOF) {
-			break
-		}
+	crds := filepath.Join(p.path, packageCRDsDir)
+	if fi, err := os.Stat(crds); err == nil && fi.IsDir() {
+		us, err := (&FolderLoader{path: crds, skipNonObjects: true}).Load()
 		if err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML stream")
+			return nil, errors.Wrap(err, "cannot read package CRDs")
 		}
-		if len(bytes) == 0 {
-			continue
-		}
-		stream = append(stream, bytes)
+		return append(ps, us...), nil
 	}
 
-	return stream, nil
+	return ps, nil
 }
 
-func streamToUnstructured(stream [][]byte) ([]*unstructured.Unstructured, error) {
-	manifests := make([]*unstructured.Unstructured, 0, len(stream))
+// isPackageDir returns true if the supplied directory looks like an unpacked
+// package, i.e. it has a package.yaml file at its root.
+func isPackageDir(dir string) bool {
+	fi, err := os.Stat(filepath.Join(dir, packageFileName))
+	return err == nil && !fi.IsDir()
+}
 
-	for _, y := range stream {
-		u := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal(y, u); err != nil {
-			return nil, errors.Wrap(err, "cannot parse YAML manifest")
-		}
-		manifests = append(manifests, u)
+func isManifestFile(info os.FileInfo) bool {
+	if info.IsDir() {
+		return false
 	}
+	switch filepath.Ext(info.Name()) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// isObjectManifest returns true if the supplied manifest has both an
+// apiVersion and a kind, i.e. it's a Kubernetes object. Manifests that can't
+// be parsed are considered objects, so that the error is reported when they
+// are loaded.
+func isObjectManifest(m []byte) bool {
+	tm := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(m, &tm); err != nil {
+		return true
+	}
+	return tm.APIVersion != "" && tm.Kind != ""
+}
+
+func readFile(path string) ([][]byte, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open file")
+	}
+	defer f.Close() //nolint:errcheck // Only open for reading.
 
-	return manifests, nil
+	return manifest.Split(f)
 }
@@ -17,6 +17,9 @@ limitations under the License.
 package validate
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -82,6 +85,22 @@ func TestFileLoaderLoad(t *testing.T) {
 				},
 			},
 		},
+		"SuccessJSON": {
+			reason: "Successfully load resources from a JSON file containing an array",
+			args: args{
+				Path: "testdata/resources.json",
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: coolResource,
+					},
+					{
+						Object: coolerResource,
+					},
+				},
+			},
+		},
 		"Error": {
 			reason: "Error loading resources from file",
 			args: args{
@@ -139,6 +158,16 @@ func TestFolderLoaderLoad(t *testing.T) {
 				},
 			},
 		},
+		"NotAnObject": {
+			reason: "Should return an error if a resource isn't a Kubernetes object",
+			args: args{
+				Path: "testdata/crds-tree/nested",
+			},
+			want: want{
+				resources: nil,
+				err:       cmpopts.AnyError,
+			},
+		},
 		"Error": {
 			reason: "Error loading resources from folder",
 			args: args{
@@ -167,63 +196,125 @@ func TestFolderLoaderLoad(t *testing.T) {
 	}
 }
 
-func TestStreamToUnstructured(t *testing.T) {
-	type args struct {
-		stream [][]byte
+func TestFolderLoaderLoadCRDs(t *testing.T) {
+	tree, err := filepath.Abs("testdata/crds-tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := t.TempDir()
+	if err := os.Symlink(filepath.Join(tree, "bucket.yaml"), filepath.Join(links, "bucket.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(links, filepath.Join(links, "loop")); err != nil {
+		t.Fatal(err)
 	}
+
 	type want struct {
-		resources []*unstructured.Unstructured
-		err       error
+		crds []string
+		err  error
 	}
 	cases := map[string]struct {
 		reason string
-		args   args
+		path   string
 		want   want
 	}{
-		"Success": {
-			reason: "Successfully parse stream to unstructured resources",
-			args: args{
-				stream: [][]byte{
-					[]byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: test"),
-				},
-			},
+		"NestedFolders": {
+			reason: "Should load the CRDs in all nested folders, skipping documents that aren't Kubernetes objects",
+			path:   tree,
 			want: want{
-				resources: []*unstructured.Unstructured{
-					{
-						Object: map[string]interface{}{
-							"apiVersion": "v1",
-							"kind":       "Pod",
-							"metadata": map[string]interface{}{
-								"name": "test",
-							},
-						},
-					},
-				},
+				crds: []string{"buckets.test.org", "databases.test.org"},
 			},
 		},
-		"Error": {
-			reason: "Error parsing stream to unstructured resources",
-			args: args{
-				stream: [][]byte{
-					[]byte("this is not a yaml"),
-				},
-			},
+		"Symlinks": {
+			reason: "Should load symlinks to files, but not follow symlinks to folders",
+			path:   links,
 			want: want{
-				resources: nil,
-				err:       cmpopts.AnyError,
+				crds: []string{"buckets.test.org"},
 			},
 		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := streamToUnstructured(tc.args.stream)
-			if diff := cmp.Diff(tc.want.resources, got); diff != "" {
-				t.Errorf("%s\nstreamToUnstructured(...): -want, +got:\n%s", tc.reason, diff)
+			l, err := NewExtensionLoader(tc.path)
+			if err != nil {
+				t.Fatalf("%s\nNewExtensionLoader(...): %v", tc.reason, err)
+			}
+			extensions, err := l.Load()
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nLoad(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			m := NewManager("", nil, &bytes.Buffer{})
+			if err := m.PrepExtensions(extensions); err != nil {
+				t.Fatalf("%s\nPrepExtensions(...): %v", tc.reason, err)
+			}
+			crds := make([]string, 0, len(m.crds))
+			for _, crd := range m.crds {
+				crds = append(crds, crd.GetName())
+			}
+			if diff := cmp.Diff(tc.want.crds, crds); diff != "" {
+				t.Errorf("%s\nLoad(...): -want CRDs, +got CRDs:\n%s", tc.reason, diff)
 			}
+		})
+	}
+}
 
+func TestPackageLoaderLoad(t *testing.T) {
+	type want struct {
+		kinds []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Success": {
+			reason: "Should load the package file and the CRDs of the package, ignoring examples",
+			path:   "testdata/package",
+			want: want{
+				kinds: []string{"Provider", "CustomResourceDefinition"},
+			},
+		},
+		"Configuration": {
+			reason: "Should load the XRDs and Compositions in the package file of a Configuration package",
+			path:   "testdata/configuration",
+			want: want{
+				kinds: []string{"Configuration", "CompositeResourceDefinition", "Composition", "Composition"},
+			},
+		},
+		"NotAPackage": {
+			reason: "Should return an error if the folder has no package file",
+			path:   "testdata/folder",
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &PackageLoader{path: tc.path}
+			got, err := p.Load()
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("%s\nstreamToUnstructured(...): -want error, +got error:\n%s", tc.reason, diff)
+				t.Errorf("%s\nLoad(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var kinds []string
+			for _, u := range got {
+				kinds = append(kinds, u.GetKind())
+			}
+			if diff := cmp.Diff(tc.want.kinds, kinds); diff != "" {
+				t.Errorf("%s\nLoad(...): -want kinds, +got kinds:\n%s", tc.reason, diff)
 			}
 		})
 	}
 }
+
+func TestNewLoaderPackage(t *testing.T) {
+	l, err := NewLoader("testdata/package")
+	if err != nil {
+		t.Fatalf("NewLoader(...): unexpected error: %v", err)
+	}
+	if _, ok := l.(*PackageLoader); !ok {
+		t.Errorf("NewLoader(...): want a *PackageLoader for a package folder, got %T", l)
+	}
+}
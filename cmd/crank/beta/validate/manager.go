@@ -37,6 +37,7 @@ import (
 const (
 	defaultCacheDir = ".crossplane/cache"
 	packageFileName = "package.yaml"
+	packageCRDsDir  = "crds"
 	baseLayerLabel  = "base"
 
 	refFmt   = "%s@%s"
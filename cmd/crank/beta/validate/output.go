@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"io"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Output formats.
+const (
+	OutputDefault = "default"
+	OutputJSON    = "json"
+	OutputYAML    = "yaml"
+	OutputJUnit   = "junit"
+)
+
+const (
+	errFmtUnknownOutput = "unknown output format %q"
+	errMarshalResults   = "cannot marshal validation results"
+)
+
+// A ResultStatus is the outcome of validating a resource.
+type ResultStatus string
+
+// Validation outcomes.
+const (
+	ResultStatusSuccess       ResultStatus = "Success"
+	ResultStatusFailure       ResultStatus = "Failure"
+	ResultStatusMissingSchema ResultStatus = "MissingSchema"
+)
+
+// Results are the structured results of validating resources.
+type Results struct {
+	Resources []ResourceResult `json:"resources"`
+	Summary   Summary          `json:"summary"`
+}
+
+// A ResourceResult is the result of validating a single resource.
+type ResourceResult struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Status     ResultStatus      `json:"status"`
+	Errors     []ValidationError `json:"errors,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+
+	// MissingSchemas are the GroupVersionKinds whose schema couldn't be
+	// found to validate the resource, if its status is MissingSchema.
+	MissingSchemas []string `json:"missingSchemas,omitempty"`
+}
+
+// A ValidationError is an error found validating a resource.
+type ValidationError struct {
+	// Type of validation that found the error, one of schema, CEL, CEL cost
+	// budget or composition.
+	Type    string `json:"type"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// A Summary counts the resources validated by outcome.
+type Summary struct {
+	Total          int `json:"total"`
+	Success        int `json:"success"`
+	Failure        int `json:"failure"`
+	MissingSchemas int `json:"missingSchemas"`
+}
+
+// StructuredValidation validates the supplied resources against the supplied
+// CRDs, and Compositions among them, like SchemaValidation, writing the
+// results to w in the supplied format, one of json, yaml or junit. It returns an error if any resource failed
+// validation.
+func StructuredValidation(resources []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition, opts Options, format string, w io.Writer) error {
+	marshal := json.Marshal
+	switch format {
+	case OutputJSON:
+	case OutputYAML:
+		marshal = yaml.Marshal
+	case OutputJUnit:
+		marshal = marshalJUnit
+	default:
+		return errors.Errorf(errFmtUnknownOutput, format)
+	}
+
+	results, err := validateAll(resources, crds, opts)
+	if err != nil {
+		return err
+	}
+
+	out := toResults(results)
+	var v any = out
+	if format == OutputJUnit {
+		v = toJUnit(out)
+	}
+	b, err := marshal(v)
+	if err != nil {
+		return errors.Wrap(err, errMarshalResults)
+	}
+	if _, err := w.Write(b); err != nil {
+		return errors.Wrap(err, errWriteOutput)
+	}
+	if format == OutputJSON || format == OutputJUnit {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return errors.Wrap(err, errWriteOutput)
+		}
+	}
+
+	if out.Summary.Failure > 0 {
+		return errors.New(errInvalidResources)
+	}
+	return nil
+}
+
+// toResults converts the supplied results to their structured representation.
+func toResults(in []resourceResult) Results {
+	out := Results{Resources: make([]ResourceResult, 0, len(in))}
+	for _, r := range in {
+		rr := ResourceResult{
+			APIVersion: r.resource.GetAPIVersion(),
+			Kind:       r.resource.GetKind(),
+			Name:       getResourceName(r.resource),
+			Status:     ResultStatusSuccess,
+			Warnings:   r.warns,
+		}
+		switch {
+		case len(r.missing) != 0:
+			rr.Status = ResultStatusMissingSchema
+			for _, gvk := range r.missing {
+				rr.MissingSchemas = append(rr.MissingSchemas, gvk.String())
+			}
+			out.Summary.MissingSchemas++
+		case len(r.errs) != 0:
+			rr.Status = ResultStatusFailure
+			out.Summary.Failure++
+		default:
+			out.Summary.Success++
+		}
+		for _, e := range r.errs {
+			rr.Errors = append(rr.Errors, ValidationError{Type: e.kind, Field: e.err.Field, Message: e.err.ErrorBody()})
+		}
+		out.Resources = append(out.Resources, rr)
+	}
+	out.Summary.Total = len(in)
+	return out
+}
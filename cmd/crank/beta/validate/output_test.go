@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/cmd/crank/internal/manifest"
+)
+
+func TestStructuredValidation(t *testing.T) {
+	resource := func(kind, name string, replicas any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "test.org/v1alpha1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+				},
+			},
+		}
+	}
+	mixed := []*unstructured.Unstructured{
+		resource("Test", "valid", int64(1)),
+		resource("Test", "invalid", "one"),
+		resource("Other", "other", int64(1)),
+	}
+	mixedResults := Results{
+		Resources: []ResourceResult{
+			{APIVersion: "test.org/v1alpha1", Kind: "Test", Name: "valid", Status: ResultStatusSuccess},
+			{APIVersion: "test.org/v1alpha1", Kind: "Test", Name: "invalid", Status: ResultStatusFailure, Errors: []ValidationError{
+				{Type: "schema", Field: "spec.replicas"},
+			}},
+			{APIVersion: "test.org/v1alpha1", Kind: "Other", Name: "other", Status: ResultStatusMissingSchema, MissingSchemas: []string{"test.org/v1alpha1, Kind=Other"}},
+		},
+		Summary: Summary{Total: 3, Success: 1, Failure: 1, MissingSchemas: 1},
+	}
+
+	type args struct {
+		resources []*unstructured.Unstructured
+		format    string
+	}
+	type want struct {
+		results Results
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"YAML": {
+			reason: "Should write valid YAML results for both passing and failing resources, and return an error",
+			args: args{
+				resources: mixed,
+				format:    OutputYAML,
+			},
+			want: want{
+				results: mixedResults,
+				err:     errors.New(errInvalidResources),
+			},
+		},
+		"JSON": {
+			reason: "Should write the same results as JSON",
+			args: args{
+				resources: mixed,
+				format:    OutputJSON,
+			},
+			want: want{
+				results: mixedResults,
+				err:     errors.New(errInvalidResources),
+			},
+		},
+		"Success": {
+			reason: "Should not return an error if all resources passed validation",
+			args: args{
+				resources: []*unstructured.Unstructured{resource("Test", "valid", int64(1))},
+				format:    OutputYAML,
+			},
+			want: want{
+				results: Results{
+					Resources: []ResourceResult{
+						{APIVersion: "test.org/v1alpha1", Kind: "Test", Name: "valid", Status: ResultStatusSuccess},
+					},
+					Summary: Summary{Total: 1, Success: 1},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := StructuredValidation(tc.args.resources, []*extv1.CustomResourceDefinition{testCRD}, Options{}, tc.args.format, w)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("%s\nStructuredValidation(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			// YAML is a superset of JSON, so this parses both formats.
+			got := Results{}
+			if err := yaml.UnmarshalStrict(w.Bytes(), &got); err != nil {
+				t.Fatalf("%s\nStructuredValidation(...): invalid output: %v\n%s", tc.reason, err, w.String())
+			}
+			if diff := cmp.Diff(tc.want.results, got, cmpopts.IgnoreFields(ValidationError{}, "Message")); diff != "" {
+				t.Errorf("%s\nStructuredValidation(...): -want results, +got results:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStructuredValidationJUnit(t *testing.T) {
+	resource := func(kind, name string, replicas any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "test.org/v1alpha1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+				},
+			},
+		}
+	}
+	mixed := []*unstructured.Unstructured{
+		resource("Test", "valid", int64(1)),
+		resource("Test", "invalid", "one"),
+		resource("Other", "other", int64(1)),
+	}
+
+	w := &bytes.Buffer{}
+	err := StructuredValidation(mixed, []*extv1.CustomResourceDefinition{testCRD}, Options{}, OutputJUnit, w)
+	if diff := cmp.Diff(errors.New(errInvalidResources), err, test.EquateErrors()); diff != "" {
+		t.Errorf("StructuredValidation(...): -want error, +got error:\n%s", diff)
+	}
+
+	want, err := os.ReadFile("testdata/junit.xml")
+	if err != nil {
+		t.Fatalf("cannot read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), w.String()); diff != "" {
+		t.Errorf("StructuredValidation(...): -want junit.xml, +got:\n%s", diff)
+	}
+}
+
+func TestStructuredValidationConfiguration(t *testing.T) {
+	extensions, err := (&PackageLoader{path: "testdata/package"}).Load()
+	if err != nil {
+		t.Fatalf("Load(...): unexpected error: %v", err)
+	}
+	resources, err := (&PackageLoader{path: "testdata/configuration"}).Load()
+	if err != nil {
+		t.Fatalf("Load(...): unexpected error: %v", err)
+	}
+	pkgExtensions, resources := manifest.SplitExtensions(resources)
+	m := NewManager(defaultCacheDir, afero.NewMemMapFs(), &bytes.Buffer{})
+	if err := m.PrepExtensions(append(extensions, pkgExtensions...)); err != nil {
+		t.Fatalf("PrepExtensions(...): unexpected error: %v", err)
+	}
+
+	w := &bytes.Buffer{}
+	err = StructuredValidation(resources, m.crds, Options{}, OutputJUnit, w)
+	if diff := cmp.Diff(errors.New(errInvalidResources), err, test.EquateErrors()); diff != "" {
+		t.Errorf("StructuredValidation(...): -want error, +got error:\n%s", diff)
+	}
+
+	got := JUnitTestSuites{}
+	if err := xml.Unmarshal(w.Bytes(), &got); err != nil {
+		t.Fatalf("StructuredValidation(...): invalid output: %v\n%s", err, w.String())
+	}
+	failures := map[string]int{}
+	for _, s := range got.Suites {
+		for _, tc := range s.TestCases {
+			failures[tc.Name] = len(tc.Failures)
+		}
+	}
+	want := map[string]int{
+		"Configuration/configuration-cool":              0,
+		"Composition/xcoolresources.example.org":        0,
+		"Composition/xcoolresources-broken.example.org": 1,
+	}
+	if diff := cmp.Diff(want, failures); diff != "" {
+		t.Errorf("StructuredValidation(...): -want failures by test case, +got failures by test case:\n%s", diff)
+	}
+	if got.Failures != 1 {
+		t.Errorf("StructuredValidation(...): want 1 failure reported for the Composition, got %d", got.Failures)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	ext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -28,15 +30,18 @@ import (
 	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	runtimeschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	celconfig "k8s.io/apiserver/pkg/apis/cel"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
+	"github.com/crossplane/crossplane/cmd/crank/internal/normalize"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
 )
 
 const (
-	errWriteOutput = "cannot write output"
+	errWriteOutput      = "cannot write output"
+	errInvalidResources = "could not validate all resources"
 )
 
 func newValidatorsAndStructurals(crds []*extv1.CustomResourceDefinition) (map[runtimeschema.GroupVersionKind][]*validation.SchemaValidator, map[runtimeschema.GroupVersionKind]*schema.Structural, error) {
@@ -90,70 +95,292 @@ func newValidatorsAndStructurals(crds []*extv1.CustomResourceDefinition) (map[ru
 	return validators, structurals, nil
 }
 
-// SchemaValidation validates the resources against the given CRDs.
-func SchemaValidation(resources []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition, skipSuccessLogs bool, w io.Writer) error { //nolint:gocognit // printing the output increases the cyclomatic complexity a little bit
-	schemaValidators, structurals, err := newValidatorsAndStructurals(crds)
+// Options configures how validation results are reported.
+type Options struct {
+	// SkipSuccessLogs skips printing the resources validated successfully.
+	SkipSuccessLogs bool
+
+	// DumpRendered prints the resources failing validation as YAML, right
+	// after their validation errors.
+	DumpRendered bool
+
+	// GroupByResource prints all the results of a resource together, under a
+	// header identifying it, sorting resources by GroupVersionKind and name.
+	GroupByResource bool
+
+	// Explain appends a short explanation and a suggestion to validation
+	// errors belonging to common categories, e.g. type mismatches.
+	Explain bool
+}
+
+// resourceResult is the result of validating a single resource.
+type resourceResult struct {
+	resource *unstructured.Unstructured
+	// missing are the GroupVersionKinds whose schema is needed to validate
+	// the resource but couldn't be found, i.e. the resource's own for most
+	// resources, or the ones of its composite and composed resources for a
+	// Composition.
+	missing []runtimeschema.GroupVersionKind
+	errs    []validationError
+	warns   []string
+}
+
+// fieldErrors returns the validation errors of the resource.
+func (r resourceResult) fieldErrors() field.ErrorList {
+	errs := make(field.ErrorList, 0, len(r.errs))
+	for _, e := range r.errs {
+		errs = append(errs, e.err)
+	}
+	return errs
+}
+
+// validationError is an error found validating a resource, either against its
+// OpenAPI schema or its CEL rules, or validating a Composition as the
+// Composition admission webhook would.
+type validationError struct {
+	kind string
+	err  *field.Error
+}
+
+// SchemaValidation validates the resources against the given CRDs, and
+// Compositions among them as the Composition admission webhook would.
+func SchemaValidation(resources []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition, opts Options, w io.Writer) error {
+	results, err := validateAll(resources, crds, opts)
 	if err != nil {
-		return errors.Wrap(err, "cannot create schema validators")
+		return err
+	}
+
+	printFn := printResults
+	if opts.GroupByResource {
+		printFn = printGroupedResults
+	}
+	if err := printFn(w, results, opts); err != nil {
+		return errors.Wrap(err, errWriteOutput)
 	}
 
 	failure, missingSchemas := 0, 0
+	for _, r := range results {
+		switch {
+		case len(r.missing) != 0:
+			missingSchemas++
+		case len(r.errs) != 0:
+			failure++
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "Total %d resources: %d missing schemas, %d success cases, %d failure cases\n", len(resources), missingSchemas, len(resources)-failure-missingSchemas, failure); err != nil {
+		return errors.Wrap(err, errWriteOutput)
+	}
 
-	for i, r := range resources {
+	if failure > 0 {
+		return errors.New(errInvalidResources)
+	}
+
+	return nil
+}
+
+// validateAll validates the resources against the given CRDs, and Compositions
+// among them as the Composition admission webhook would, returning a result
+// for each resource in the order they were supplied. Validating Compositions
+// against the Composition schema alone would miss most issues.
+func validateAll(resources []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition, opts Options) ([]resourceResult, error) {
+	comps := make([]*unstructured.Unstructured, 0)
+	others := make([]*unstructured.Unstructured, 0, len(resources))
+	for _, r := range resources {
+		if isComposition(r) {
+			comps = append(comps, r)
+			continue
+		}
+		others = append(others, r)
+	}
+
+	compResults, err := validateCompositions(comps, crds)
+	if err != nil {
+		return nil, err
+	}
+	otherResults, err := validateResources(others, crds)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]resourceResult, 0, len(resources))
+	for _, r := range resources {
+		if isComposition(r) {
+			results, compResults = append(results, compResults[0]), compResults[1:]
+			continue
+		}
+		results, otherResults = append(results, otherResults[0]), otherResults[1:]
+	}
+
+	if opts.Explain {
+		for i := range results {
+			for j := range results[i].errs {
+				results[i].errs[j].err = explain(results[i].errs[j].err)
+			}
+		}
+	}
+	return results, nil
+}
+
+// validateResources validates the resources against the schemas and CEL rules
+// of the given CRDs, returning a result for each of them.
+func validateResources(resources []*unstructured.Unstructured, crds []*extv1.CustomResourceDefinition) ([]resourceResult, error) {
+	schemaValidators, structurals, err := newValidatorsAndStructurals(crds)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create schema validators")
+	}
+
+	results := make([]resourceResult, 0, len(resources))
+	for _, r := range resources {
+		res := resourceResult{resource: r}
 		gvk := r.GetObjectKind().GroupVersionKind()
 		sv, ok := schemaValidators[gvk]
 		if !ok {
-			missingSchemas++
-			if _, err := fmt.Fprintf(w, "[!] could not find CRD/XRD for: %s\n", r.GroupVersionKind().String()); err != nil {
-				return errors.Wrap(err, errWriteOutput)
-			}
-
+			res.missing = []runtimeschema.GroupVersionKind{gvk}
+			results = append(results, res)
 			continue
 		}
 
-		rf := 0
-
 		for _, v := range sv {
-			re := validation.ValidateCustomResource(nil, r, *v)
-			for _, e := range re {
-				rf++
-				if _, err := fmt.Fprintf(w, "[x] schema validation error %s, %s : %s\n", r.GroupVersionKind().String(), getResourceName(r), e.Error()); err != nil {
-					return errors.Wrap(err, errWriteOutput)
-				}
+			for _, e := range validation.ValidateCustomResource(nil, r, *v) {
+				res.errs = append(res.errs, validationError{kind: "schema", err: e})
 			}
 
 			s := structurals[gvk] // if we have a schema validator, we should also have a structural
 
 			celValidator := cel.NewValidator(s, true, celconfig.PerCallLimit)
-			re, _ = celValidator.Validate(context.TODO(), nil, s, resources[i].Object, nil, celconfig.PerCallLimit)
+			re, _ := celValidator.Validate(context.TODO(), nil, s, r.Object, nil, celconfig.RuntimeCELCostBudget)
 			for _, e := range re {
-				rf++
-				if _, err := fmt.Fprintf(w, "[x] CEL validation error %s, %s : %s\n", r.GroupVersionKind().String(), getResourceName(r), e.Error()); err != nil {
-					return errors.Wrap(err, errWriteOutput)
+				kind := "CEL"
+				if isCELCostBudgetError(e) {
+					kind = "CEL cost budget"
 				}
+				res.errs = append(res.errs, validationError{kind: kind, err: e})
 			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
 
-			if rf == 0 && !skipSuccessLogs {
-				if _, err := fmt.Fprintf(w, "[✓] %s, %s validated successfully\n", r.GroupVersionKind().String(), getResourceName(r)); err != nil {
-					return errors.Wrap(err, errWriteOutput)
-				}
-			} else {
-				failure++
+// isCELCostBudgetError returns true if the supplied CEL validation error
+// reports a rule exceeding either its own or the overall cost budget, rather
+// than a failing rule. Both are reported as invalid values, so they can only
+// be told apart by their detail.
+func isCELCostBudgetError(e *field.Error) bool {
+	return strings.Contains(e.Detail, "call cost exceeds limit") ||
+		strings.Contains(e.Detail, "running out of cost budget")
+}
+
+// printResults prints the results of each resource in the order resources
+// were validated, one line per validation error.
+func printResults(w io.Writer, results []resourceResult, opts Options) error {
+	for _, res := range results {
+		r := res.resource
+		if len(res.missing) != 0 {
+			if err := printMissingSchemas(w, res); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, warn := range res.warns {
+			if _, err := fmt.Fprintf(w, "[!] %s, %s : %s\n", r.GroupVersionKind().String(), getResourceName(r), warn); err != nil {
+				return err
 			}
 		}
+		for _, e := range res.errs {
+			if _, err := fmt.Fprintf(w, "[x] %s validation error %s, %s : %s\n", e.kind, r.GroupVersionKind().String(), getResourceName(r), e.err); err != nil {
+				return err
+			}
+		}
+		if err := printResultFooter(w, res, opts); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if _, err := fmt.Fprintf(w, "Total %d resources: %d missing schemas, %d success cases, %d failure cases\n", len(resources), missingSchemas, len(resources)-failure-missingSchemas, failure); err != nil {
-		return errors.Wrap(err, errWriteOutput)
+// printGroupedResults prints all the results of a resource together under a
+// header identifying it, sorting resources by GroupVersionKind and name.
+func printGroupedResults(w io.Writer, results []resourceResult, opts Options) error {
+	sorted := make([]resourceResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		gi, gj := sorted[i].resource.GroupVersionKind().String(), sorted[j].resource.GroupVersionKind().String()
+		if gi != gj {
+			return gi < gj
+		}
+		return getResourceName(sorted[i].resource) < getResourceName(sorted[j].resource)
+	})
+
+	for _, res := range sorted {
+		r := res.resource
+		if len(res.missing) != 0 {
+			if err := printMissingSchemas(w, res); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case len(res.errs) != 0:
+			if _, err := fmt.Fprintf(w, "[x] %s, %s\n", r.GroupVersionKind().String(), getResourceName(r)); err != nil {
+				return err
+			}
+		case len(res.warns) != 0:
+			if _, err := fmt.Fprintf(w, "[!] %s, %s\n", r.GroupVersionKind().String(), getResourceName(r)); err != nil {
+				return err
+			}
+		}
+		for _, warn := range res.warns {
+			if _, err := fmt.Fprintf(w, "    warning: %s\n", warn); err != nil {
+				return err
+			}
+		}
+		for _, e := range res.errs {
+			if _, err := fmt.Fprintf(w, "    %s validation error: %s\n", e.kind, e.err); err != nil {
+				return err
+			}
+		}
+		if err := printResultFooter(w, res, opts); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if failure > 0 {
-		return errors.New("could not validate all resources")
+// printMissingSchemas prints a line for each schema that couldn't be found to
+// validate the resource.
+func printMissingSchemas(w io.Writer, res resourceResult) error {
+	for _, gvk := range res.missing {
+		if _, err := fmt.Fprintf(w, "[!] could not find CRD/XRD for: %s\n", gvk.String()); err != nil {
+			return err
+		}
 	}
-
 	return nil
 }
 
+// printResultFooter prints what follows the validation errors of a resource:
+// the resource itself if it failed validation and DumpRendered is set, or a
+// success line if it passed validation and SkipSuccessLogs isn't set.
+func printResultFooter(w io.Writer, res resourceResult, opts Options) error {
+	if len(res.errs) != 0 {
+		if !opts.DumpRendered {
+			return nil
+		}
+		b, err := normalize.YAML(res.resource)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	if opts.SkipSuccessLogs {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "[✓] %s, %s validated successfully\n", res.resource.GroupVersionKind().String(), getResourceName(res.resource))
+	return err
+}
+
 func getResourceName(r *unstructured.Unstructured) string {
 	if r.GetName() != "" {
 		return r.GetName()
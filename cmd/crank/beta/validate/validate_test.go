@@ -18,6 +18,8 @@ package validate
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -1303,7 +1305,7 @@ func TestValidateResources(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			got := SchemaValidation(tc.args.resources, tc.args.crds, false, w)
+			got := SchemaValidation(tc.args.resources, tc.args.crds, Options{}, w)
 
 			if diff := cmp.Diff(tc.want.err, got, test.EquateErrors()); diff != "" {
 				t.Errorf("%s\nvalidateResources(...): -want error, +got error:\n%s", tc.reason, diff)
@@ -1311,3 +1313,219 @@ func TestValidateResources(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateResourcesDumpRendered(t *testing.T) {
+	invalid := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "test.org/v1alpha1",
+			"kind":       "Test",
+			"metadata": map[string]interface{}{
+				"name": "test",
+			},
+			"spec": map[string]interface{}{
+				"replicas": "one",
+			},
+		},
+	}
+	rendered := `---
+apiVersion: test.org/v1alpha1
+kind: Test
+metadata:
+  name: test
+spec:
+  replicas: one
+`
+
+	cases := map[string]struct {
+		reason       string
+		dumpRendered bool
+		want         bool
+	}{
+		"DumpRendered": {
+			reason:       "Should print the resources failing validation if dumpRendered is set",
+			dumpRendered: true,
+			want:         true,
+		},
+		"NoDumpRendered": {
+			reason:       "Should not print the resources failing validation if dumpRendered is not set",
+			dumpRendered: false,
+			want:         false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			_ = SchemaValidation([]*unstructured.Unstructured{invalid}, []*extv1.CustomResourceDefinition{testCRD}, Options{DumpRendered: tc.dumpRendered}, w)
+
+			if got := strings.Contains(w.String(), rendered); got != tc.want {
+				t.Errorf("%s\nSchemaValidation(...): want rendered resource in output %t, got output:\n%s", tc.reason, tc.want, w.String())
+			}
+		})
+	}
+}
+
+func TestSchemaValidationFromStdin(t *testing.T) {
+	stream := `---
+apiVersion: test.org/v1alpha1
+kind: Test
+metadata:
+  name: first
+spec:
+  replicas: 1
+---
+apiVersion: test.org/v1alpha1
+kind: Test
+metadata:
+  name: second
+spec:
+  replicas: two
+---
+`
+	resources, err := (&StdinLoader{in: strings.NewReader(stream)}).Load()
+	if err != nil {
+		t.Fatalf("Load(...): %v", err)
+	}
+
+	w := &bytes.Buffer{}
+	_ = SchemaValidation(resources, []*extv1.CustomResourceDefinition{testCRD}, Options{}, w)
+
+	want := "Total 2 resources: 0 missing schemas, 1 success cases, 1 failure cases"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("SchemaValidation(...): want both resources read from stdin to be validated, got output:\n%s", w.String())
+	}
+}
+
+func TestSchemaValidationCEL(t *testing.T) {
+	expensiveCRD := testCRDWithCEL.DeepCopy()
+	expensiveCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"items": {
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+				XValidations: extv1.ValidationRules{{
+					Rule:    "self.all(x, self.all(y, x == y || x != y))",
+					Message: "items should be comparable",
+				}},
+			},
+		},
+	}
+	limitedCRD := testCRDWithCEL.DeepCopy()
+	limitedCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"items": {
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+				XValidations: extv1.ValidationRules{{
+					Rule:    "size(self) <= 2",
+					Message: "at most two items are allowed",
+				}},
+			},
+		},
+	}
+	items := make([]interface{}, 0, 500)
+	for i := 0; i < cap(items); i++ {
+		items = append(items, fmt.Sprintf("item-%d", i))
+	}
+
+	resource := func(spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "test.org/v1alpha1",
+				"kind":       "Test",
+				"metadata": map[string]interface{}{
+					"name": "test",
+				},
+				"spec": spec,
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason   string
+		resource *unstructured.Unstructured
+		crd      *extv1.CustomResourceDefinition
+		want     string
+		wantNot  string
+	}{
+		"FailingRule": {
+			reason:   "Should report the message and field path of a failing CEL rule",
+			resource: resource(map[string]interface{}{"replicas": int64(5), "minReplicas": int64(1), "maxReplicas": int64(3)}),
+			crd:      testCRDWithCEL,
+			want:     "[x] CEL validation error test.org/v1alpha1, Kind=Test, test : spec: Invalid value: \"object\": replicas should be in between minReplicas and maxReplicas",
+		},
+		"FailingRuleCustomMessage": {
+			reason:   "Should report the custom message of a failing CEL rule as a CEL error, not a cost budget one",
+			resource: resource(map[string]interface{}{"items": []interface{}{"a", "b", "c"}}),
+			crd:      limitedCRD,
+			want:     "[x] CEL validation error test.org/v1alpha1, Kind=Test, test : spec.items: Invalid value: \"array\": at most two items are allowed",
+			wantNot:  "cost budget",
+		},
+		"CostBudgetExceeded": {
+			reason:   "Should report a distinct error when a CEL rule exceeds its cost budget",
+			resource: resource(map[string]interface{}{"items": items}),
+			crd:      expensiveCRD,
+			want:     "[x] CEL cost budget validation error test.org/v1alpha1, Kind=Test, test : spec.items",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			_ = SchemaValidation([]*unstructured.Unstructured{tc.resource}, []*extv1.CustomResourceDefinition{tc.crd}, Options{}, w)
+			if !strings.Contains(w.String(), tc.want) {
+				t.Errorf("%s\nSchemaValidation(...): want output to contain %q, got:\n%s", tc.reason, tc.want, w.String())
+			}
+			if tc.wantNot != "" && strings.Contains(w.String(), tc.wantNot) {
+				t.Errorf("%s\nSchemaValidation(...): want output not to contain %q, got:\n%s", tc.reason, tc.wantNot, w.String())
+			}
+		})
+	}
+}
+
+func TestValidateResourcesGroupByResource(t *testing.T) {
+	invalid := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "test.org/v1alpha1",
+				"kind":       "Test",
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{},
+			},
+		}
+	}
+
+	w := &bytes.Buffer{}
+	_ = SchemaValidation([]*unstructured.Unstructured{invalid("b"), invalid("a")}, []*extv1.CustomResourceDefinition{testCRDWithCEL}, Options{GroupByResource: true}, w)
+
+	// Each resource should have a header, followed by all of its errors,
+	// resources should be sorted by name.
+	var headers []string
+	errs := map[string]int{}
+	for _, l := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		switch {
+		case strings.HasPrefix(l, "[x] "):
+			headers = append(headers, l)
+		case strings.HasPrefix(l, "    "):
+			if len(headers) == 0 {
+				t.Fatalf("SchemaValidation(...): validation error printed before any resource header:\n%s", w.String())
+			}
+			errs[headers[len(headers)-1]]++
+		}
+	}
+
+	want := []string{
+		"[x] test.org/v1alpha1, Kind=Test, a",
+		"[x] test.org/v1alpha1, Kind=Test, b",
+	}
+	if diff := cmp.Diff(want, headers); diff != "" {
+		t.Errorf("SchemaValidation(...): -want headers, +got headers:\n%s", diff)
+	}
+	for _, h := range want {
+		if errs[h] < 2 {
+			t.Errorf("SchemaValidation(...): want multiple errors grouped under %q, got %d:\n%s", h, errs[h], w.String())
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifest decodes the streams of Kubernetes manifests read by the
+// CLI from files, folders or standard input, either as YAML or JSON.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtDecode      = "cannot decode manifests from %s"
+	errFmtParseObject = "cannot parse document %d of %s"
+)
+
+// Decode decodes the supplied input into objects. The input can either be a
+// YAML stream, a stream of JSON objects, or a JSON array of objects. The
+// supplied source, e.g. the name of the file the input was read from, is
+// reported in errors.
+func Decode(source string, r io.Reader) ([]*unstructured.Unstructured, error) {
+	stream, err := Split(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtDecode, source)
+	}
+	return Unmarshal(source, stream)
+}
+
+// Split splits the supplied input into manifests. The input can either be a
+// YAML stream, or a stream of JSON objects and arrays of objects. YAML
+// documents only containing separators, comments or whitespace are skipped.
+func Split(r io.Reader) ([][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read input")
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")) {
+		return splitJSONStream(trimmed)
+	}
+
+	stream := make([][]byte, 0)
+
+	yr := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+	for {
+		doc, err := yr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse YAML stream")
+		}
+		if isEmptyDocument(doc) {
+			continue
+		}
+		stream = append(stream, doc)
+	}
+
+	return stream, nil
+}
+
+// Unmarshal unmarshals each of the supplied manifests into an object. Errors
+// identify the manifest by its index in the stream and the supplied source.
+func Unmarshal(source string, stream [][]byte) ([]*unstructured.Unstructured, error) {
+	manifests := make([]*unstructured.Unstructured, 0, len(stream))
+
+	for i, y := range stream {
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(y, u); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseObject, i, source)
+		}
+		manifests = append(manifests, u)
+	}
+
+	return manifests, nil
+}
+
+// IsExtension returns true if the supplied object defines the schema of other
+// objects, i.e. it's a CRD or an XRD.
+func IsExtension(u *unstructured.Unstructured) bool {
+	switch u.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+		schema.GroupKind{Group: "apiextensions.crossplane.io", Kind: "CompositeResourceDefinition"}:
+		return true
+	}
+	return false
+}
+
+// SplitExtensions splits the supplied objects into the ones defining the
+// schema of other objects, i.e. CRDs and XRDs, and all the others, preserving
+// their order.
+func SplitExtensions(us []*unstructured.Unstructured) (extensions, others []*unstructured.Unstructured) {
+	extensions = make([]*unstructured.Unstructured, 0)
+	others = make([]*unstructured.Unstructured, 0, len(us))
+	for _, u := range us {
+		if IsExtension(u) {
+			extensions = append(extensions, u)
+			continue
+		}
+		others = append(others, u)
+	}
+	return extensions, others
+}
+
+// isEmptyDocument returns true if the supplied YAML document only contains
+// separators, comments or whitespace, e.g. the ones produced by leading or
+// trailing separators.
+func isEmptyDocument(doc []byte) bool {
+	for _, l := range bytes.Split(doc, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 || bytes.Equal(l, []byte("---")) || bytes.HasPrefix(l, []byte("#")) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// splitJSONStream splits the supplied stream of JSON objects and arrays of
+// objects into manifests, one per object.
+func splitJSONStream(data []byte) ([][]byte, error) {
+	stream := make([][]byte, 0)
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw json.RawMessage
+		err := d.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse JSON stream")
+		}
+		if raw[0] == '[' {
+			items := make([]json.RawMessage, 0)
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, errors.Wrap(err, "cannot parse JSON array")
+			}
+			for _, i := range items {
+				stream = append(stream, i)
+			}
+			continue
+		}
+		stream = append(stream, raw)
+	}
+	return stream, nil
+}
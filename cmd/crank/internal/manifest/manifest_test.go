@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSplit(t *testing.T) {
+	type args struct {
+		input string
+	}
+	type want struct {
+		stream []string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"YAMLStream": {
+			reason: "Successfully split a YAML stream",
+			args: args{
+				input: "---\na: b\n---\nc: d\n",
+			},
+			want: want{
+				stream: []string{"---\na: b\n", "c: d\n"},
+			},
+		},
+		"YAMLStreamWithEmptyDocuments": {
+			reason: "Skip empty documents, e.g. the ones produced by leading and trailing separators",
+			args: args{
+				input: "---\n---\na: b\n---\n# Just a comment\n---\nc: d\n---\n",
+			},
+			want: want{
+				stream: []string{"a: b\n", "c: d\n"},
+			},
+		},
+		"JSONObjects": {
+			reason: "Successfully split a stream of JSON objects",
+			args: args{
+				input: `{"a": "b"}
+{"c": "d"}`,
+			},
+			want: want{
+				stream: []string{`{"a": "b"}`, `{"c": "d"}`},
+			},
+		},
+		"JSONArray": {
+			reason: "Successfully split a JSON array of objects",
+			args: args{
+				input: `  [{"a": "b"}, {"c": "d"}]`,
+			},
+			want: want{
+				stream: []string{`{"a": "b"}`, `{"c": "d"}`},
+			},
+		},
+		"JSONObjectsAndArrays": {
+			reason: "Successfully split a stream of JSON objects and arrays of objects",
+			args: args{
+				input: `[{"a": "b"}, {"c": "d"}]
+{"e": "f"}`,
+			},
+			want: want{
+				stream: []string{`{"a": "b"}`, `{"c": "d"}`, `{"e": "f"}`},
+			},
+		},
+		"InvalidJSONArray": {
+			reason: "Return an error for a malformed JSON array",
+			args: args{
+				input: `[{"a": "b"}`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Split(strings.NewReader(tc.args.input))
+			var stream []string
+			for _, b := range got {
+				stream = append(stream, string(b))
+			}
+			if diff := cmp.Diff(tc.want.stream, stream); diff != "" {
+				t.Errorf("%s\nSplit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nSplit(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type args struct {
+		stream [][]byte
+	}
+	type want struct {
+		resources []*unstructured.Unstructured
+		err       error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Successfully parse stream to unstructured resources",
+			args: args{
+				stream: [][]byte{
+					[]byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: test"),
+				},
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]interface{}{
+							"apiVersion": "v1",
+							"kind":       "Pod",
+							"metadata": map[string]interface{}{
+								"name": "test",
+							},
+						},
+					},
+				},
+			},
+		},
+		"Error": {
+			reason: "Error parsing stream to unstructured resources",
+			args: args{
+				stream: [][]byte{
+					[]byte("this is not a yaml"),
+				},
+			},
+			want: want{
+				resources: nil,
+				err:       cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Unmarshal("test.yaml", tc.args.stream)
+			if diff := cmp.Diff(tc.want.resources, got); diff != "" {
+				t.Errorf("%s\nUnmarshal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nUnmarshal(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type args struct {
+		source string
+		input  string
+	}
+	type want struct {
+		names []string
+		err   string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"YAMLStream": {
+			reason: "Should decode each document of a YAML stream",
+			args: args{
+				source: "resources.yaml",
+				input:  "apiVersion: v1\nkind: Pod\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: b\n",
+			},
+			want: want{
+				names: []string{"a", "b"},
+			},
+		},
+		"InvalidDocument": {
+			reason: "Should report the source and index of a document that can't be parsed",
+			args: args{
+				source: "resources.yaml",
+				input:  "apiVersion: v1\nkind: Pod\nmetadata:\n  name: a\n---\nthis is not an object\n",
+			},
+			want: want{
+				err: "cannot parse document 1 of resources.yaml",
+			},
+		},
+		"InvalidStream": {
+			reason: "Should report the source of a stream that can't be split",
+			args: args{
+				source: "stdin",
+				input:  `[{"a": "b"}`,
+			},
+			want: want{
+				err: "cannot decode manifests from stdin",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Decode(tc.args.source, strings.NewReader(tc.args.input))
+			var names []string
+			for _, u := range got {
+				names = append(names, u.GetName())
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("%s\nDecode(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+			if tc.want.err == "" && err != nil {
+				t.Errorf("%s\nDecode(...): unexpected error: %v", tc.reason, err)
+			}
+			if tc.want.err != "" && (err == nil || !strings.HasPrefix(err.Error(), tc.want.err)) {
+				t.Errorf("%s\nDecode(...): want error starting with %q, got %v", tc.reason, tc.want.err, err)
+			}
+		})
+	}
+}
+
+func TestSplitExtensions(t *testing.T) {
+	obj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		}}
+	}
+	crd := obj("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crd")
+	xrd := obj("apiextensions.crossplane.io/v1", "CompositeResourceDefinition", "xrd")
+	comp := obj("apiextensions.crossplane.io/v1", "Composition", "comp")
+	pod := obj("v1", "Pod", "pod")
+
+	type want struct {
+		extensions []*unstructured.Unstructured
+		others     []*unstructured.Unstructured
+	}
+	cases := map[string]struct {
+		reason string
+		in     []*unstructured.Unstructured
+		want   want
+	}{
+		"Mixed": {
+			reason: "Should split CRDs and XRDs from any other object, preserving their order",
+			in:     []*unstructured.Unstructured{comp, xrd, pod, crd},
+			want: want{
+				extensions: []*unstructured.Unstructured{xrd, crd},
+				others:     []*unstructured.Unstructured{comp, pod},
+			},
+		},
+		"NoExtensions": {
+			reason: "Should return no extensions if there are no CRDs or XRDs",
+			in:     []*unstructured.Unstructured{pod},
+			want: want{
+				others: []*unstructured.Unstructured{pod},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			extensions, others := SplitExtensions(tc.in)
+			if diff := cmp.Diff(tc.want.extensions, extensions, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nSplitExtensions(...): -want extensions, +got extensions:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.others, others, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nSplitExtensions(...): -want others, +got others:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
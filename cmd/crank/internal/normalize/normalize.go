@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package normalize strips volatile fields from resources output by the CLI,
+// so that the output is stable and can be compared against golden files.
+package normalize
+
+import (
+	"bytes"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// VolatileMetadataFields are the metadata fields set by the API server that
+// change between otherwise identical resources.
+var VolatileMetadataFields = []string{
+	"creationTimestamp",
+	"generation",
+	"managedFields",
+	"resourceVersion",
+	"uid",
+}
+
+// Unstructured returns a copy of the supplied resource without any of the
+// VolatileMetadataFields. Metadata left empty is removed altogether.
+func Unstructured(u *unstructured.Unstructured) *unstructured.Unstructured {
+	out := u.DeepCopy()
+	meta, ok := out.Object["metadata"].(map[string]any)
+	if !ok {
+		return out
+	}
+	for _, f := range VolatileMetadataFields {
+		delete(meta, f)
+	}
+	if len(meta) == 0 {
+		delete(out.Object, "metadata")
+	}
+	return out
+}
+
+// WithoutNulls returns a copy of the supplied resource without any field set
+// to null, e.g. by a patch, at any depth. Server-side apply drops them too.
+// Null array items are left untouched.
+func WithoutNulls(u *unstructured.Unstructured) *unstructured.Unstructured {
+	out := u.DeepCopy()
+	pruneNulls(out.Object)
+	return out
+}
+
+func pruneNulls(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, f := range v {
+			if f == nil {
+				delete(v, k)
+				continue
+			}
+			pruneNulls(f)
+		}
+	case []any:
+		for _, i := range v {
+			pruneNulls(i)
+		}
+	}
+}
+
+// YAML returns a YAML stream of the supplied resources, normalized using
+// Unstructured. Map keys are always serialized in sorted order.
+func YAML(us ...*unstructured.Unstructured) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, u := range us {
+		// sigs.k8s.io/yaml goes through encoding/json, which sorts map keys.
+		b, err := yaml.Marshal(Unstructured(u).Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal %s %q to YAML", u.GetKind(), u.GetName())
+		}
+		buf.WriteString("---\n")
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
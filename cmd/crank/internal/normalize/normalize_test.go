@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package normalize
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestUnstructured(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		u      *unstructured.Unstructured
+		want   *unstructured.Unstructured
+	}{
+		"StripVolatileMetadata": {
+			reason: "Should remove all volatile metadata fields, leaving the others untouched.",
+			u: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"metadata": map[string]any{
+					"name":              "test",
+					"labels":            map[string]any{"a": "b"},
+					"uid":               "e5a1b8c6-1d1b-4b1e-9b1a-2b9d1f7e9c1a",
+					"resourceVersion":   "42",
+					"generation":        int64(3),
+					"creationTimestamp": "2024-01-01T00:00:00Z",
+					"managedFields":     []any{map[string]any{"manager": "crossplane"}},
+				},
+				"spec": map[string]any{"uid": "kept"},
+			}},
+			want: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"metadata": map[string]any{
+					"name":   "test",
+					"labels": map[string]any{"a": "b"},
+				},
+				"spec": map[string]any{"uid": "kept"},
+			}},
+		},
+		"RemoveEmptyMetadata": {
+			reason: "Should remove metadata left empty once volatile fields are stripped.",
+			u: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"metadata": map[string]any{
+					"creationTimestamp": nil,
+				},
+			}},
+			want: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+			}},
+		},
+		"NoMetadata": {
+			reason: "Should return resources without metadata unchanged.",
+			u: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+			}},
+			want: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in := tc.u.DeepCopy()
+			got := Unstructured(tc.u)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nUnstructured(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(in, tc.u); diff != "" {
+				t.Errorf("\n%s\nUnstructured(...): must not modify its input: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithoutNulls(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		u      *unstructured.Unstructured
+		want   *unstructured.Unstructured
+	}{
+		"PruneNestedNulls": {
+			reason: "Should remove all fields set to null, including the ones of objects nested in arrays.",
+			u: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"metadata": map[string]any{
+					"name":   "test",
+					"labels": nil,
+				},
+				"spec": map[string]any{
+					"a": "b",
+					"c": nil,
+					"d": map[string]any{"e": nil},
+					"f": []any{map[string]any{"g": nil, "h": "i"}, nil},
+				},
+			}},
+			want: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"metadata": map[string]any{
+					"name": "test",
+				},
+				"spec": map[string]any{
+					"a": "b",
+					"d": map[string]any{},
+					"f": []any{map[string]any{"h": "i"}, nil},
+				},
+			}},
+		},
+		"NoNulls": {
+			reason: "Should return resources without nulls unchanged.",
+			u: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"spec":       map[string]any{"a": "b"},
+			}},
+			want: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Test",
+				"spec":       map[string]any{"a": "b"},
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in := tc.u.DeepCopy()
+			got := WithoutNulls(tc.u)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWithoutNulls(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(in, tc.u); diff != "" {
+				t.Errorf("\n%s\nWithoutNulls(...): must not modify its input: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestYAML(t *testing.T) {
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		us     []*unstructured.Unstructured
+		want   want
+	}{
+		"SortedStream": {
+			reason: "Should return a normalized YAML stream with sorted keys.",
+			us: []*unstructured.Unstructured{
+				{Object: map[string]any{
+					"kind":       "Test",
+					"apiVersion": "example.org/v1",
+					"metadata":   map[string]any{"uid": "abc", "name": "a"},
+					"spec":       map[string]any{"z": "z", "a": "a"},
+				}},
+				{Object: map[string]any{
+					"kind":       "Test",
+					"apiVersion": "example.org/v1",
+					"metadata":   map[string]any{"name": "b", "resourceVersion": "1"},
+				}},
+			},
+			want: want{
+				out: `---
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: a
+spec:
+  a: a
+  z: z
+---
+apiVersion: example.org/v1
+kind: Test
+metadata:
+  name: b
+`,
+			},
+		},
+		"Empty": {
+			reason: "Should return an empty stream if no resources are supplied.",
+			want: want{
+				out: "",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := YAML(tc.us...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nYAML(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(got)); diff != "" {
+				t.Errorf("\n%s\nYAML(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -28,21 +28,30 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 )
 
-// NewCoreCRDsMigrator returns a new *CoreCRDsMigrator.
-func NewCoreCRDsMigrator(crdName, sourceVersion string) *CoreCRDsMigrator {
+// AnnotationKeyMigrationContinue is the annotation recording on a CRD the
+// continue token of the next page of resources to migrate to its storage
+// version, so that an interrupted migration resumes from there rather than
+// starting over. It's removed once the migration completes.
+const AnnotationKeyMigrationContinue = "crossplane.io/storage-version-migration-continue"
+
+// NewCoreCRDsMigrator returns a new *CoreCRDsMigrator migrating the resources
+// of the named CRD stored as any of the supplied source versions to its
+// storage version.
+func NewCoreCRDsMigrator(crdName string, sourceVersions ...string) *CoreCRDsMigrator {
 	c := &CoreCRDsMigrator{
-		crdName:    crdName,
-		oldVersion: sourceVersion,
+		crdName:     crdName,
+		oldVersions: sourceVersions,
 	}
 	return c
 }
 
 // CoreCRDsMigrator makes sure the CRDs are using the latest storage version.
 type CoreCRDsMigrator struct {
-	crdName    string
-	oldVersion string
+	crdName     string
+	oldVersions []string
 }
 
 // Run applies all CRDs in the given directory.
@@ -56,10 +65,11 @@ func (c *CoreCRDsMigrator) Run(ctx context.Context, kube client.Client) error {
 		return errors.Wrapf(err, "cannot get %s crd", c.crdName)
 	}
 	// no old version in the crd, nothing to do
-	if !sets.NewString(crd.Status.StoredVersions...).Has(c.oldVersion) {
+	if !sets.NewString(crd.Status.StoredVersions...).HasAny(c.oldVersions...) {
 		return nil
 	}
-	// we need to patch all resources to the new storage version
+	// we need to patch all resources to the new storage version, a single
+	// sweep migrates resources stored as any of the old versions
 	var storageVersion string
 	for _, v := range crd.Spec.Versions {
 		if v.Storage {
@@ -73,12 +83,19 @@ func (c *CoreCRDsMigrator) Run(ctx context.Context, kube client.Client) error {
 		Version: storageVersion,
 		Kind:    crd.Spec.Names.ListKind,
 	})
-	var continueToken string
+	// resume from the checkpoint of an interrupted migration, if any
+	continueToken := crd.GetAnnotations()[AnnotationKeyMigrationContinue]
 	for {
-		if err := kube.List(ctx, &resources,
+		err := kube.List(ctx, &resources,
 			client.Limit(500),
 			client.Continue(continueToken),
-		); err != nil {
+		)
+		if kerrors.IsResourceExpired(err) && continueToken != "" {
+			// the checkpoint is too old to resume from, start over
+			continueToken = ""
+			continue
+		}
+		if err != nil {
 			return errors.Wrapf(err, "cannot list %s", resources.GroupVersionKind().String())
 		}
 		for i := range resources.Items {
@@ -89,6 +106,9 @@ func (c *CoreCRDsMigrator) Run(ctx context.Context, kube client.Client) error {
 			}
 		}
 		continueToken = resources.GetContinue()
+		if err := c.checkpoint(ctx, kube, &crd, continueToken); err != nil {
+			return err
+		}
 		if continueToken == "" {
 			break
 		}
@@ -110,3 +130,18 @@ func (c *CoreCRDsMigrator) Run(ctx context.Context, kube client.Client) error {
 
 	return nil
 }
+
+// checkpoint records the supplied continue token on the CRD, or removes the
+// recorded one if the token is empty.
+func (c *CoreCRDsMigrator) checkpoint(ctx context.Context, kube client.Client, crd *extv1.CustomResourceDefinition, continueToken string) error {
+	if crd.GetAnnotations()[AnnotationKeyMigrationContinue] == continueToken {
+		return nil
+	}
+	origCrd := crd.DeepCopy()
+	if continueToken == "" {
+		meta.RemoveAnnotations(crd, AnnotationKeyMigrationContinue)
+	} else {
+		meta.AddAnnotations(crd, map[string]string{AnnotationKeyMigrationContinue: continueToken})
+	}
+	return errors.Wrapf(kube.Patch(ctx, crd, client.MergeFrom(origCrd)), "cannot record migration checkpoint on %s crd", c.crdName)
+}
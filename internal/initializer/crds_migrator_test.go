@@ -0,0 +1,316 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initializer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestCoreCRDsMigrator(t *testing.T) {
+	crd := func(storedVersions ...string) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group: "example.org",
+				Names: extv1.CustomResourceDefinitionNames{Kind: "Cool", ListKind: "CoolList"},
+				Versions: []extv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1"},
+					{Name: "v1beta1"},
+					{Name: "v1", Storage: true},
+				},
+			},
+			Status: extv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+
+	type args struct {
+		sourceVersions []string
+		crd            *extv1.CustomResourceDefinition
+		listErr        error
+	}
+	type want struct {
+		err            error
+		patched        []string
+		storedVersions []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CRDNotFound": {
+			reason: "We should not return an error if the CRD doesn't exist.",
+			args: args{
+				sourceVersions: []string{"v1alpha1"},
+			},
+		},
+		"NoOldVersionStored": {
+			reason: "We should not migrate anything if none of the source versions is stored.",
+			args: args{
+				sourceVersions: []string{"v1alpha1", "v1beta1"},
+				crd:            crd("v1"),
+			},
+			want: want{
+				storedVersions: []string{"v1"},
+			},
+		},
+		"SingleOldVersionStored": {
+			reason: "We should migrate all resources and only keep the storage version if the source version is stored.",
+			args: args{
+				sourceVersions: []string{"v1alpha1"},
+				crd:            crd("v1alpha1", "v1"),
+			},
+			want: want{
+				patched:        []string{"cool-a", "cool-b"},
+				storedVersions: []string{"v1"},
+			},
+		},
+		"MultipleOldVersionsStored": {
+			reason: "We should migrate all resources once and remove all the stale source versions, keeping the storage version.",
+			args: args{
+				sourceVersions: []string{"v1alpha1", "v1beta1"},
+				crd:            crd("v1alpha1", "v1beta1", "v1"),
+			},
+			want: want{
+				patched:        []string{"cool-a", "cool-b"},
+				storedVersions: []string{"v1"},
+			},
+		},
+		"ListError": {
+			reason: "We should return any error encountered listing the resources to migrate.",
+			args: args{
+				sourceVersions: []string{"v1alpha1", "v1beta1"},
+				crd:            crd("v1beta1", "v1"),
+				listErr:        errBoom,
+			},
+			want: want{
+				err:            errors.Wrapf(errBoom, "cannot list %s", schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "CoolList"}.String()),
+				storedVersions: []string{"v1beta1", "v1"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var patched []string
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.args.crd == nil {
+						return kerrors.NewNotFound(schema.GroupResource{}, "")
+					}
+					tc.args.crd.DeepCopyInto(obj.(*extv1.CustomResourceDefinition))
+					return nil
+				},
+				MockList: func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+					if tc.args.listErr != nil {
+						return tc.args.listErr
+					}
+					l := list.(*unstructured.UnstructuredList)
+					for _, n := range []string{"cool-a", "cool-b"} {
+						u := unstructured.Unstructured{}
+						u.SetName(n)
+						l.Items = append(l.Items, u)
+					}
+					return nil
+				},
+				MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+					patched = append(patched, obj.GetName())
+					return nil
+				},
+				MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					tc.args.crd.Status.StoredVersions = obj.(*extv1.CustomResourceDefinition).Status.StoredVersions
+					return nil
+				},
+			}
+
+			err := NewCoreCRDsMigrator("cools.example.org", tc.args.sourceVersions...).Run(context.Background(), kube)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.patched, patched); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want patched, +got patched:\n%s", tc.reason, diff)
+			}
+			if tc.args.crd == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.storedVersions, tc.args.crd.Status.StoredVersions); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want stored versions, +got stored versions:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCoreCRDsMigratorResume(t *testing.T) {
+	// pages of resources to migrate by continue token, with the token of the
+	// next page.
+	pages := map[string]struct {
+		names []string
+		next  string
+	}{
+		"":       {names: []string{"cool-a", "cool-b"}, next: "page-2"},
+		"page-2": {names: []string{"cool-c", "cool-d"}},
+	}
+
+	type run struct {
+		failPatching string
+		wantErr      error
+		wantPatched  []string
+	}
+	type want struct {
+		checkpoint     string
+		storedVersions []string
+	}
+
+	cases := map[string]struct {
+		reason     string
+		checkpoint string
+		runs       []run
+		want       want
+	}{
+		"Complete": {
+			reason: "We should clear the checkpoint once all pages are migrated.",
+			runs: []run{
+				{wantPatched: []string{"cool-a", "cool-b", "cool-c", "cool-d"}},
+			},
+			want: want{
+				storedVersions: []string{"v1"},
+			},
+		},
+		"ResumeAfterInterruption": {
+			reason: "We should resume an interrupted migration from the first page not fully migrated, rather than starting over.",
+			runs: []run{
+				{
+					failPatching: "cool-c",
+					wantErr:      errors.Wrapf(errBoom, "cannot patch %s %q", "Cool", "cool-c"),
+					wantPatched:  []string{"cool-a", "cool-b"},
+				},
+				{wantPatched: []string{"cool-c", "cool-d"}},
+			},
+			want: want{
+				storedVersions: []string{"v1"},
+			},
+		},
+		"RestartAfterExpiredCheckpoint": {
+			reason:     "We should start over if the recorded checkpoint expired.",
+			checkpoint: "expired",
+			runs: []run{
+				{wantPatched: []string{"cool-a", "cool-b", "cool-c", "cool-d"}},
+			},
+			want: want{
+				storedVersions: []string{"v1"},
+			},
+		},
+		"Interrupted": {
+			reason: "We should record the first page not fully migrated if the migration is interrupted.",
+			runs: []run{
+				{
+					failPatching: "cool-d",
+					wantErr:      errors.Wrapf(errBoom, "cannot patch %s %q", "Cool", "cool-d"),
+					wantPatched:  []string{"cool-a", "cool-b", "cool-c"},
+				},
+			},
+			want: want{
+				checkpoint:     "page-2",
+				storedVersions: []string{"v1alpha1", "v1"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd := &extv1.CustomResourceDefinition{
+				Spec: extv1.CustomResourceDefinitionSpec{
+					Group:    "example.org",
+					Names:    extv1.CustomResourceDefinitionNames{Kind: "Cool", ListKind: "CoolList"},
+					Versions: []extv1.CustomResourceDefinitionVersion{{Name: "v1alpha1"}, {Name: "v1", Storage: true}},
+				},
+				Status: extv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1alpha1", "v1"}},
+			}
+			if tc.checkpoint != "" {
+				crd.SetAnnotations(map[string]string{AnnotationKeyMigrationContinue: tc.checkpoint})
+			}
+
+			for i, r := range tc.runs {
+				var patched []string
+				kube := &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						crd.DeepCopyInto(obj.(*extv1.CustomResourceDefinition))
+						return nil
+					},
+					MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+						lo := &client.ListOptions{}
+						lo.ApplyOptions(opts)
+						p, ok := pages[lo.Continue]
+						if !ok {
+							return kerrors.NewResourceExpired("continue token expired")
+						}
+						l := list.(*unstructured.UnstructuredList)
+						l.Items = nil
+						for _, n := range p.names {
+							u := unstructured.Unstructured{}
+							u.SetName(n)
+							l.Items = append(l.Items, u)
+						}
+						l.SetContinue(p.next)
+						return nil
+					},
+					MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						if c, ok := obj.(*extv1.CustomResourceDefinition); ok {
+							crd.SetAnnotations(c.GetAnnotations())
+							return nil
+						}
+						if obj.GetName() == r.failPatching {
+							return errBoom
+						}
+						patched = append(patched, obj.GetName())
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+						crd.Status.StoredVersions = obj.(*extv1.CustomResourceDefinition).Status.StoredVersions
+						return nil
+					},
+				}
+
+				err := NewCoreCRDsMigrator("cools.example.org", "v1alpha1").Run(context.Background(), kube)
+				if diff := cmp.Diff(r.wantErr, err, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nRun(...) #%d: -want err, +got err:\n%s", tc.reason, i, diff)
+				}
+				if diff := cmp.Diff(r.wantPatched, patched); diff != "" {
+					t.Errorf("\n%s\nRun(...) #%d: -want patched, +got patched:\n%s", tc.reason, i, diff)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.checkpoint, crd.GetAnnotations()[AnnotationKeyMigrationContinue]); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want checkpoint, +got checkpoint:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.storedVersions, crd.Status.StoredVersions); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want stored versions, +got stored versions:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
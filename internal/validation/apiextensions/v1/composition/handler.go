@@ -20,6 +20,8 @@ package composition
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -51,6 +53,8 @@ const (
 
 	errFmtTooManyCRDs = "more than one CRD found for %s.%s: %v"
 	errFmtGetCRDs     = "cannot get the needed CRDs: %v"
+
+	warnFmtSkippedSchemaValidation = "Composition %q was not validated against the schemas of the resources it composes, cannot find the CRDs of: %s"
 )
 
 // SetupWebhookWithManager sets up the webhook with the manager.
@@ -103,7 +107,7 @@ func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (adm
 
 	// Get all the needed CRDs, Composite Resource, Managed resources ... ?
 	// Error out if missing in strict mode
-	gkToCRD, errs := v.getNeededCRDs(ctx, comp)
+	gkToCRD, missing, errs := v.getNeededCRDs(ctx, comp)
 	// If we have errors, and we are in strict mode or any of the errors is not
 	// a NotFound, return them.
 	if len(errs) != 0 {
@@ -111,16 +115,17 @@ func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) (adm
 			return warns, errors.Errorf(errFmtGetCRDs, errs)
 		}
 		// If we have errors, but we are not in strict mode, and all of the
-		// errors are not found errors, just move them to warnings and skip any
-		// further validation.
+		// errors are not found errors, warn about the missing CRDs and skip
+		// any further validation.
 
 		// TODO(phisco): we are playing it safe and skipping validation
 		// altogether, in the future we might want to also support partially
 		// available inputs.
-		for _, err := range errs {
-			warns = append(warns, err.Error())
+		names := make([]string, 0, len(missing))
+		for _, gk := range missing {
+			names = append(names, gk.String())
 		}
-		return warns, nil
+		return append(warns, fmt.Sprintf(warnFmtSkippedSchemaValidation, comp.GetName(), strings.Join(names, ", "))), nil
 	}
 
 	cv, err := composition.NewValidator(
@@ -165,9 +170,15 @@ func containsOtherThanNotFound(errs []error) bool {
 	return false
 }
 
-func (v *validator) getNeededCRDs(ctx context.Context, comp *v1.Composition) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, []error) {
+// getNeededCRDs returns the CRDs of the composite resource and of the composed
+// resources of the supplied Composition, including the ones embedded in the
+// input of function-patch-and-transform pipeline steps, by group and kind, along with the
+// groups and kinds of the ones that couldn't be found. Not found errors are
+// returned along any other error. Each CRD is looked up at most once.
+func (v *validator) getNeededCRDs(ctx context.Context, comp *v1.Composition) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, []schema.GroupKind, []error) {
 	// TODO(negz): Use https://pkg.go.dev/errors#Join to return a single error?
 	var resultErrs []error
+	var missing []schema.GroupKind
 	neededCrds := make(map[schema.GroupKind]apiextensions.CustomResourceDefinition)
 
 	// Get schema for the Composite Resource Definition defined by
@@ -178,35 +189,55 @@ func (v *validator) getNeededCRDs(ctx context.Context, comp *v1.Composition) (ma
 	compositeCRD, err := v.getCRD(ctx, &compositeResGK)
 	if err != nil {
 		if !kerrors.IsNotFound(err) {
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		}
 		resultErrs = append(resultErrs, err)
+		missing = append(missing, compositeResGK)
 	}
 	if compositeCRD != nil {
 		neededCrds[compositeResGK] = *compositeCRD
 	}
 
 	// Get schema for all Managed Resource Definitions defined by
-	// comp.Spec.Resources.
+	// comp.Spec.Resources, or by the input of function-patch-and-transform
+	// pipeline steps.
+	gks := make([]schema.GroupKind, 0, len(comp.Spec.Resources))
 	for _, res := range comp.Spec.Resources {
 		res := res
 		gvk, err := composition.GetBaseObjectGVK(&res)
 		if err != nil {
-			return nil, []error{err}
+			return nil, nil, []error{err}
+		}
+		gks = append(gks, gvk.GroupKind())
+	}
+	for _, res := range composition.PatchAndTransformInputResources(comp) {
+		res := res
+		gvk, err := composition.GetBaseObjectGVK(&res)
+		if err != nil {
+			// Invalid function inputs are left to the function to reject.
+			continue
+		}
+		gks = append(gks, gvk.GroupKind())
+	}
+	for _, gk := range gks {
+		if _, ok := neededCrds[gk]; ok || slices.Contains(missing, gk) {
+			// Already looked up, e.g. for another composed resource of the
+			// same kind.
+			continue
 		}
-		gk := gvk.GroupKind()
 		crd, err := v.getCRD(ctx, &gk)
 		switch {
 		case kerrors.IsNotFound(err):
 			resultErrs = append(resultErrs, err)
+			missing = append(missing, gk)
 		case err != nil:
-			return nil, []error{err}
+			return nil, nil, []error{err}
 		case crd != nil:
 			neededCrds[gk] = *crd
 		}
 	}
 
-	return neededCrds, resultErrs
+	return neededCrds, missing, resultErrs
 }
 
 // getCRD returns the validation schema for the given GVK, by looking up the CRD
@@ -16,6 +16,360 @@ limitations under the License.
 
 package composition
 
-import "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/features"
+)
 
 var _ admission.CustomValidator = &validator{}
+
+func TestValidateCreate(t *testing.T) {
+	withSchemaValidation := &feature.Flags{}
+	withSchemaValidation.Enable(features.EnableBetaCompositionWebhookSchemaValidation)
+
+	comp := func(mode v1.CompositionValidationMode, base string, patches ...v1.Patch) *v1.Composition {
+		return &v1.Composition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cool",
+				Annotations: map[string]string{v1.SchemaAwareCompositionValidationModeAnnotation: string(mode)},
+			},
+			Spec: v1.CompositionSpec{
+				CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XCool"},
+				Resources: []v1.ComposedTemplate{{
+					Name:    ptr.To("nop"),
+					Base:    runtime.RawExtension{Raw: []byte(base)},
+					Patches: patches,
+				}},
+			},
+		}
+	}
+	crd := func(group, kind string, spec extv1.JSONSchemaProps) extv1.CustomResourceDefinition {
+		return extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group: group,
+				Names: extv1.CustomResourceDefinitionNames{Kind: kind},
+				Scope: extv1.ClusterScoped,
+				Versions: []extv1.CustomResourceDefinitionVersion{{
+					Name:    "v1alpha1",
+					Served:  true,
+					Storage: true,
+					Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: &extv1.JSONSchemaProps{
+						Type:       "object",
+						Properties: map[string]extv1.JSONSchemaProps{"spec": spec},
+					}},
+				}},
+			},
+		}
+	}
+	stringField := extv1.JSONSchemaProps{Type: "string"}
+	crds := map[string]extv1.CustomResourceDefinition{
+		"XCool.example.org": crd("example.org", "XCool", extv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]extv1.JSONSchemaProps{"coolField": stringField},
+		}),
+		"NopResource.nop.example.org": crd("nop.example.org", "NopResource", extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{"forProvider": {
+				Type:       "object",
+				Properties: map[string]extv1.JSONSchemaProps{"field": stringField},
+			}},
+		}),
+	}
+	// withCRDs returns a reader listing the supplied CRDs by the group and kind
+	// they define, as the webhook's index does.
+	withCRDs := func(crds map[string]extv1.CustomResourceDefinition) client.Reader {
+		return &test.MockClient{
+			MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+				lo := &client.ListOptions{}
+				lo.ApplyOptions(opts)
+				gk, _ := lo.FieldSelector.RequiresExactMatch(crdsIndexKey)
+				if c, ok := crds[gk]; ok {
+					list.(*extv1.CustomResourceDefinitionList).Items = []extv1.CustomResourceDefinition{c}
+				}
+				return nil
+			},
+		}
+	}
+
+	nop := `{"apiVersion":"nop.example.org/v1alpha1","kind":"NopResource"}`
+	invalidPatch := v1.Patch{
+		Type:          v1.PatchTypeFromCompositeFieldPath,
+		FromFieldPath: ptr.To("spec.uncoolField"),
+		ToFieldPath:   ptr.To("spec.forProvider.field"),
+	}
+
+	// pipeline returns a Composition running function-patch-and-transform
+	// with the supplied patches on a NopResource.
+	pipeline := func(mode v1.CompositionValidationMode, patches ...v1.Patch) *v1.Composition {
+		in, _ := json.Marshal(map[string]any{
+			"apiVersion": "pt.fn.crossplane.io/v1beta1",
+			"kind":       "Resources",
+			"resources": []v1.ComposedTemplate{{
+				Name:    ptr.To("nop"),
+				Base:    runtime.RawExtension{Raw: []byte(nop)},
+				Patches: patches,
+			}},
+		})
+		return &v1.Composition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cool",
+				Annotations: map[string]string{v1.SchemaAwareCompositionValidationModeAnnotation: string(mode)},
+			},
+			Spec: v1.CompositionSpec{
+				CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XCool"},
+				Mode:             ptr.To(v1.CompositionModePipeline),
+				Pipeline: []v1.PipelineStep{{
+					Step:        "patch-and-transform",
+					FunctionRef: v1.FunctionReference{Name: "function-patch-and-transform"},
+					Input:       &runtime.RawExtension{Raw: in},
+				}},
+			},
+		}
+	}
+
+	type args struct {
+		reader   client.Reader
+		features *feature.Flags
+		comp     *v1.Composition
+	}
+	type want struct {
+		warns admission.Warnings
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"LogicalValidationWarnings": {
+			reason: "Warnings about a valid Composition should be returned as admission warnings.",
+			args: args{
+				comp: comp(v1.SchemaAwareCompositionValidationModeWarn, `{"apiVersion":"nop.example.org/v1alpha1","kind":"NopResource","status":{"atProvider":{"id":"cool"}}}`),
+			},
+			want: want{
+				warns: admission.Warnings{
+					"spec.resources[0].base.status: base should only describe the desired state of the composed resource, its status is never composed",
+				},
+			},
+		},
+		"MissingCRDsInLooseMode": {
+			reason: "CRDs missing in loose mode should be listed in an admission warning, as schema-aware validation is skipped.",
+			args: args{
+				reader:   withCRDs(nil),
+				features: withSchemaValidation,
+				comp:     comp(v1.SchemaAwareCompositionValidationModeLoose, nop, invalidPatch),
+			},
+			want: want{
+				warns: admission.Warnings{
+					`Composition "cool" was not validated against the schemas of the resources it composes, cannot find the CRDs of: XCool.example.org, NopResource.nop.example.org`,
+				},
+			},
+		},
+		"SomeCRDsMissingInLooseMode": {
+			reason: "Only the CRDs actually missing in loose mode should be listed in the admission warning, once each.",
+			args: args{
+				reader: withCRDs(map[string]extv1.CustomResourceDefinition{
+					"XCool.example.org": crds["XCool.example.org"],
+				}),
+				features: withSchemaValidation,
+				comp: func() *v1.Composition {
+					c := comp(v1.SchemaAwareCompositionValidationModeLoose, nop, invalidPatch)
+					c.Spec.Resources = append(c.Spec.Resources, v1.ComposedTemplate{
+						Name: ptr.To("other-nop"),
+						Base: runtime.RawExtension{Raw: []byte(nop)},
+					})
+					return c
+				}(),
+			},
+			want: want{
+				warns: admission.Warnings{
+					`Composition "cool" was not validated against the schemas of the resources it composes, cannot find the CRDs of: NopResource.nop.example.org`,
+				},
+			},
+		},
+		"SchemaErrorsInWarnMode": {
+			reason: "Schema-aware validation errors in warn mode should be returned as admission warnings.",
+			args: args{
+				reader:   withCRDs(crds),
+				features: withSchemaValidation,
+				comp:     comp(v1.SchemaAwareCompositionValidationModeWarn, nop, invalidPatch),
+			},
+			want: want{
+				warns: admission.Warnings{
+					`Composition "cool" invalid for schema-aware validation: spec.resources[0].patches[0].fromFieldPath: Invalid value: "spec.uncoolField": field 'uncoolField' is not valid according to the schema`,
+				},
+			},
+		},
+		"PipelineWithPatchAndTransformInput": {
+			reason: "A valid pipeline Composition patching resources through function-patch-and-transform should be admitted in strict mode.",
+			args: args{
+				reader:   withCRDs(crds),
+				features: withSchemaValidation,
+				comp: pipeline(v1.SchemaAwareCompositionValidationModeStrict, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.coolField"),
+					ToFieldPath:   ptr.To("spec.forProvider.field"),
+				}),
+			},
+		},
+		"SchemaErrorsInPatchAndTransformInputInWarnMode": {
+			reason: "Schema-aware validation errors of function-patch-and-transform inputs in warn mode should be returned as admission warnings.",
+			args: args{
+				reader:   withCRDs(crds),
+				features: withSchemaValidation,
+				comp:     pipeline(v1.SchemaAwareCompositionValidationModeWarn, invalidPatch),
+			},
+			want: want{
+				warns: admission.Warnings{
+					`Composition "cool" invalid for schema-aware validation: spec.pipeline[0].input.resources[0].patches[0].fromFieldPath: Invalid value: "spec.uncoolField": field 'uncoolField' is not valid according to the schema`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := &validator{reader: tc.args.reader, options: controller.Options{Features: tc.args.features}}
+			warns, err := v.ValidateCreate(context.Background(), tc.args.comp)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCreate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warns, warns); diff != "" {
+				t.Errorf("\n%s\nValidateCreate(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetNeededCRDs(t *testing.T) {
+	comp := func(bases ...string) *v1.Composition {
+		c := &v1.Composition{
+			Spec: v1.CompositionSpec{
+				CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XCool"},
+			},
+		}
+		for _, b := range bases {
+			c.Spec.Resources = append(c.Spec.Resources, v1.ComposedTemplate{Base: runtime.RawExtension{Raw: []byte(b)}})
+		}
+		return c
+	}
+	nop := `{"apiVersion":"nop.example.org/v1alpha1","kind":"NopResource"}`
+	other := `{"apiVersion":"nop.example.org/v1alpha1","kind":"OtherResource"}`
+
+	type args struct {
+		existing []string
+		comp     *v1.Composition
+	}
+	type want struct {
+		found   []schema.GroupKind
+		missing []schema.GroupKind
+		lists   map[string]int
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SameKindComposedMultipleTimes": {
+			reason: "The CRD of a kind composed multiple times should be listed once.",
+			args: args{
+				existing: []string{"XCool.example.org", "NopResource.nop.example.org"},
+				comp:     comp(nop, nop, nop),
+			},
+			want: want{
+				found: []schema.GroupKind{{Group: "example.org", Kind: "XCool"}, {Group: "nop.example.org", Kind: "NopResource"}},
+				lists: map[string]int{"XCool.example.org": 1, "NopResource.nop.example.org": 1},
+			},
+		},
+		"MissingKindComposedMultipleTimes": {
+			reason: "The missing CRD of a kind composed multiple times should be listed, and reported missing, once.",
+			args: args{
+				existing: []string{"XCool.example.org", "NopResource.nop.example.org"},
+				comp:     comp(other, nop, other, other),
+			},
+			want: want{
+				found:   []schema.GroupKind{{Group: "example.org", Kind: "XCool"}, {Group: "nop.example.org", Kind: "NopResource"}},
+				missing: []schema.GroupKind{{Group: "nop.example.org", Kind: "OtherResource"}},
+				lists:   map[string]int{"XCool.example.org": 1, "NopResource.nop.example.org": 1, "OtherResource.nop.example.org": 1},
+			},
+		},
+		"KindsComposedByPatchAndTransform": {
+			reason: "The CRDs of the kinds composed through function-patch-and-transform inputs should be listed too.",
+			args: args{
+				existing: []string{"XCool.example.org", "NopResource.nop.example.org"},
+				comp: func() *v1.Composition {
+					c := comp(nop)
+					c.Spec.Pipeline = []v1.PipelineStep{{
+						Step:  "patch-and-transform",
+						Input: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"pt.fn.crossplane.io/v1beta1","kind":"Resources","resources":[{"name":"other","base":` + other + `}]}`)},
+					}}
+					return c
+				}(),
+			},
+			want: want{
+				found:   []schema.GroupKind{{Group: "example.org", Kind: "XCool"}, {Group: "nop.example.org", Kind: "NopResource"}},
+				missing: []schema.GroupKind{{Group: "nop.example.org", Kind: "OtherResource"}},
+				lists:   map[string]int{"XCool.example.org": 1, "NopResource.nop.example.org": 1, "OtherResource.nop.example.org": 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lists := map[string]int{}
+			v := &validator{reader: &test.MockClient{
+				MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					gk, _ := lo.FieldSelector.RequiresExactMatch(crdsIndexKey)
+					lists[gk]++
+					for _, e := range tc.args.existing {
+						if e != gk {
+							continue
+						}
+						g := schema.ParseGroupKind(gk)
+						list.(*extv1.CustomResourceDefinitionList).Items = []extv1.CustomResourceDefinition{{
+							Spec: extv1.CustomResourceDefinitionSpec{Group: g.Group, Names: extv1.CustomResourceDefinitionNames{Kind: g.Kind}},
+						}}
+					}
+					return nil
+				},
+			}}
+
+			needed, missing, _ := v.getNeededCRDs(context.Background(), tc.args.comp)
+			found := make([]schema.GroupKind, 0, len(needed))
+			for gk := range needed {
+				found = append(found, gk)
+			}
+			sortGKs := cmpopts.SortSlices(func(a, b schema.GroupKind) bool { return a.String() < b.String() })
+			if diff := cmp.Diff(tc.want.found, found, sortGKs); diff != "" {
+				t.Errorf("\n%s\ngetNeededCRDs(...): -want found, +got found:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.missing, missing, sortGKs); diff != "" {
+				t.Errorf("\n%s\ngetNeededCRDs(...): -want missing, +got missing:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.lists, lists); diff != "" {
+				t.Errorf("\n%s\ngetNeededCRDs(...): -want CRD lists, +got CRD lists:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	errFmtCompositeTypeRefIsClaim = "%s is the claim kind offered by composite resource definition %s, reference the composite resource kind instead"
+)
+
+// validateCompositeTypeRef returns an error if the Composition references the
+// claim kind offered by a composite resource definition rather than the
+// composite resource kind it defines.
+func (v *Validator) validateCompositeTypeRef(ctx context.Context, comp *v1.Composition) field.ErrorList {
+	gk := schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind).GroupKind()
+	xrd, ok := v.getClaimXRDName(ctx, gk)
+	if !ok {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("spec", "compositeTypeRef", "kind"), comp.Spec.CompositeTypeRef.Kind, fmt.Sprintf(errFmtCompositeTypeRefIsClaim, gk, xrd))}
+}
+
+// getClaimXRDName returns the name of the composite resource definition
+// offering the supplied kind as its claim, if any. It looks at the XRDs the
+// Validator was configured with first, then at the CRD of the supplied kind,
+// as claim CRDs are namespaced and controlled by the XRD offering them, while
+// composite resource CRDs are cluster scoped.
+func (v *Validator) getClaimXRDName(ctx context.Context, gk schema.GroupKind) (string, bool) {
+	for _, xrd := range v.xrds {
+		if xrd.Spec.ClaimNames != nil && xrd.Spec.Group == gk.Group && xrd.Spec.ClaimNames.Kind == gk.Kind {
+			return xrd.GetName(), true
+		}
+	}
+	// Errors getting CRDs are already reported by validatePatchesWithSchemas.
+	crd, err := v.crdGetter.Get(ctx, gk)
+	if err != nil || crd == nil || crd.Spec.Scope != apiextensions.NamespaceScoped {
+		return "", false
+	}
+	ref := metav1.GetControllerOf(crd)
+	if ref == nil || ref.Kind != v1.CompositeResourceDefinitionKind {
+		return "", false
+	}
+	return ref.Name, true
+}
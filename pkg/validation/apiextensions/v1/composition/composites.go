@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/xcrd"
+)
+
+const (
+	errRenderXRD  = "cannot derive CRD from composite resource definition"
+	errConvertCRD = "cannot convert custom resource definition"
+)
+
+// WithCompositeResourceDefinitions returns a ValidatorOption that configures
+// the Validator to validate composed resources that are themselves composite
+// resources against the schemas derived from the supplied XRDs, rather than
+// the ones returned by the CRDGetter.
+func WithCompositeResourceDefinitions(xrds ...*v1.CompositeResourceDefinition) ValidatorOption {
+	return func(v *Validator) {
+		v.xrds = append(v.xrds, xrds...)
+	}
+}
+
+// compositeCRDGetter returns the CRDs derived from XRDs for composite
+// resources, falling back to the wrapped CRDGetter for any other kind.
+type compositeCRDGetter struct {
+	CRDGetter
+	composites crdGetterMap
+}
+
+func (c compositeCRDGetter) Get(ctx context.Context, gk schema.GroupKind) (*apiextensions.CustomResourceDefinition, error) {
+	if crd, ok := c.composites[gk]; ok {
+		return &crd, nil
+	}
+	return c.CRDGetter.Get(ctx, gk)
+}
+
+func (c compositeCRDGetter) GetAll(ctx context.Context) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, error) {
+	all, err := c.CRDGetter.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[schema.GroupKind]apiextensions.CustomResourceDefinition, len(all)+len(c.composites))
+	for gk, crd := range all {
+		out[gk] = crd
+	}
+	for gk, crd := range c.composites {
+		out[gk] = crd
+	}
+	return out, nil
+}
+
+// getCompositeCRDs derives the CRDs of the composite resources defined by the
+// supplied XRDs, the same way Crossplane does when installing them.
+func getCompositeCRDs(xrds []*v1.CompositeResourceDefinition) (crdGetterMap, error) {
+	out := make(crdGetterMap, len(xrds))
+	for _, xrd := range xrds {
+		crd, err := xcrd.ForCompositeResource(xrd)
+		if err != nil {
+			return nil, errors.Wrap(err, errRenderXRD)
+		}
+		internal := apiextensions.CustomResourceDefinition{}
+		if err := extv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(crd, &internal, nil); err != nil {
+			return nil, errors.Wrap(err, errConvertCRD)
+		}
+		out[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = internal
+	}
+	return out, nil
+}
+
+// validateNestedCompositesWithSchemas validates the composed resources that
+// are themselves composite resources. Unlike managed resources, whose required
+// fields could be late initialized by their provider, nothing but the
+// Composition can set the required spec fields of a composite resource, so
+// they must be set either by the base or by a patch, unless they have a
+// default.
+func (v *Validator) validateNestedCompositesWithSchemas(ctx context.Context, comp *v1.Composition) (errs field.ErrorList) {
+	for i := range comp.Spec.Resources {
+		ct := &comp.Spec.Resources[i]
+		path := field.NewPath("spec", "resources").Index(i)
+		obj, err := GetBaseObject(ct)
+		if err != nil {
+			// Invalid bases are reported by the logical validation.
+			continue
+		}
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		// Errors getting CRDs are already reported by validatePatchesWithSchemas.
+		crd, err := v.crdGetter.Get(ctx, gvk.GroupKind())
+		if err != nil || !isCompositeCRD(crd) {
+			continue
+		}
+		s := getSchemaForVersion(crd, gvk.Version)
+		if s == nil {
+			continue
+		}
+		spec, ok := s.Properties["spec"]
+		if !ok {
+			continue
+		}
+		base, err := getSpec(obj)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Child("base"), string(ct.Base.Raw), err.Error()))
+			continue
+		}
+		patched := getPatchedFieldPaths(comp, ct)
+		for _, r := range spec.Required {
+			if _, ok := base[r]; ok {
+				continue
+			}
+			if spec.Properties[r].Default != nil {
+				continue
+			}
+			if isPatched(patched, "spec."+r) {
+				continue
+			}
+			errs = append(errs, field.Required(path.Child("base", "spec", r), "required by composite resource "+gvk.GroupKind().String()+", but neither set by the base nor patched"))
+		}
+	}
+	return errs
+}
+
+// isCompositeCRD returns true if the supplied CRD defines a composite
+// resource, i.e. it's cluster scoped and controlled by a composite resource
+// definition. This holds both for the CRDs Crossplane creates for XRDs and for
+// the ones the Validator derives from the XRDs it was configured with.
+func isCompositeCRD(crd *apiextensions.CustomResourceDefinition) bool {
+	if crd == nil || crd.Spec.Scope != apiextensions.ClusterScoped {
+		return false
+	}
+	ref := metav1.GetControllerOf(crd)
+	return ref != nil && ref.Kind == v1.CompositeResourceDefinitionKind
+}
+
+// getSpec returns the spec of the supplied object.
+func getSpec(obj runtime.Object) (map[string]any, error) {
+	p, err := fieldpath.PaveObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]any{}
+	if err := p.GetValueInto("spec", &spec); err != nil && !fieldpath.IsNotFound(err) {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// getPatchedFieldPaths returns the field paths of the composed resource the
+// patches of the supplied composed template write to, including the ones
+// coming from patch sets.
+func getPatchedFieldPaths(comp *v1.Composition, ct *v1.ComposedTemplate) []string {
+	patches := make([]v1.Patch, 0, len(ct.Patches))
+	for _, p := range ct.Patches {
+		if p.GetType() == v1.PatchTypePatchSet {
+			patches = append(patches, getPatchSetPatches(comp, p.PatchSetName)...)
+			continue
+		}
+		patches = append(patches, p)
+	}
+	paths := make([]string, 0, len(patches))
+	for _, p := range patches {
+		switch p.GetType() { //nolint:exhaustive // Only these patch types write to composed resources.
+		case v1.PatchTypeFromCompositeFieldPath, v1.PatchTypeFromEnvironmentFieldPath:
+			to := p.GetToFieldPath()
+			if to == "" {
+				to = p.GetFromFieldPath()
+			}
+			paths = append(paths, to)
+		case v1.PatchTypeCombineFromComposite, v1.PatchTypeCombineFromEnvironment:
+			paths = append(paths, p.GetToFieldPath())
+		}
+	}
+	return paths
+}
+
+// isPatched returns true if any of the supplied patched field paths writes to
+// the supplied field path, to any of its children or to any of its parents,
+// e.g. a patch writing the whole spec of a sparse base.
+func isPatched(patched []string, fieldPath string) bool {
+	for _, p := range patched {
+		if p == fieldPath || isChildFieldPath(p, fieldPath) || isChildFieldPath(fieldPath, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChildFieldPath returns true if the supplied child field path is nested
+// under the supplied parent one.
+func isChildFieldPath(child, parent string) bool {
+	return strings.HasPrefix(child, parent+".") || strings.HasPrefix(child, parent+"[")
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// A patchDependency is a composed resource patching from a composite resource
+// field another composed resource patches to.
+type patchDependency struct {
+	from, to int
+
+	// fieldPath is the composite resource field path read by the first
+	// composed resource and written by the second.
+	fieldPath string
+}
+
+// validatePatchDependencyCycles returns a warning for each cycle of composed
+// resources patching from composite resource fields the next one in the cycle
+// patches to. Such data dependencies may never converge, as each composed
+// resource waits for the others to be observed first. Composed resources
+// patching from fields they patch to themselves are not reported.
+func (v *Validator) validatePatchDependencyCycles(_ context.Context, comp *v1.Composition) (warns []string) {
+	n := len(comp.Spec.Resources)
+	reads, writes := make([][]string, n), make([][]string, n)
+	for i := range comp.Spec.Resources {
+		reads[i], writes[i] = getCompositeFieldPaths(comp, &comp.Spec.Resources[i])
+	}
+
+	deps := make([][]patchDependency, n)
+	for i := range reads {
+		for j := range writes {
+			if i == j {
+				continue
+			}
+			if fp, ok := getOverlappingFieldPath(reads[i], writes[j]); ok {
+				deps[i] = append(deps[i], patchDependency{from: i, to: j, fieldPath: fp})
+			}
+		}
+	}
+
+	// Report each composed resource in a single cycle, starting from the
+	// first one, to avoid reporting the same cycle once per member.
+	reported := make([]bool, n)
+	for i := range deps {
+		if reported[i] {
+			continue
+		}
+		cycle := findDependencyCycle(deps, i, reported)
+		if len(cycle) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(cycle))
+		steps := make([]string, 0, len(cycle))
+		for _, d := range cycle {
+			reported[d.from] = true
+			names = append(names, getComposedTemplateName(comp, d.from))
+			steps = append(steps, fmt.Sprintf("%s patches from %s, patched to by %s", getComposedTemplateName(comp, d.from), d.fieldPath, getComposedTemplateName(comp, d.to)))
+		}
+		warns = append(warns, fmt.Sprintf("%s: composed resources %s depend on each other through composite resource fields, their patches may never converge: %s", field.NewPath("spec", "resources"), strings.Join(names, ", "), strings.Join(steps, "; ")))
+	}
+	return warns
+}
+
+// findDependencyCycle returns the shortest cycle of dependencies starting and
+// ending at the supplied composed resource, ignoring the skipped ones, if any.
+func findDependencyCycle(deps [][]patchDependency, start int, skip []bool) []patchDependency {
+	// via maps each visited composed resource to the dependency it was
+	// reached through.
+	via := map[int]patchDependency{}
+	queue := []int{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, d := range deps[cur] {
+			if d.to == start {
+				cycle := []patchDependency{d}
+				for at := cur; at != start; at = via[at].from {
+					cycle = append([]patchDependency{via[at]}, cycle...)
+				}
+				return cycle
+			}
+			if _, ok := via[d.to]; ok || skip[d.to] {
+				continue
+			}
+			via[d.to] = d
+			queue = append(queue, d.to)
+		}
+	}
+	return nil
+}
+
+// getCompositeFieldPaths returns the composite resource field paths the
+// patches of the supplied composed template read from and write to, including
+// the ones coming from patch sets.
+func getCompositeFieldPaths(comp *v1.Composition, ct *v1.ComposedTemplate) (reads, writes []string) {
+	for _, p := range ct.Patches {
+		patches := []v1.Patch{p}
+		if p.GetType() == v1.PatchTypePatchSet {
+			patches = getPatchSetPatches(comp, p.PatchSetName)
+		}
+		for _, p := range patches {
+			switch p.GetType() { //nolint:exhaustive // Only these patch types read from or write to the composite resource.
+			case v1.PatchTypeFromCompositeFieldPath:
+				reads = append(reads, p.GetFromFieldPath())
+			case v1.PatchTypeCombineFromComposite:
+				if p.Combine == nil {
+					continue
+				}
+				for _, v := range p.Combine.Variables {
+					reads = append(reads, v.FromFieldPath)
+				}
+			case v1.PatchTypeToCompositeFieldPath:
+				to := p.GetToFieldPath()
+				if to == "" {
+					to = p.GetFromFieldPath()
+				}
+				writes = append(writes, to)
+			case v1.PatchTypeCombineToComposite:
+				writes = append(writes, p.GetToFieldPath())
+			}
+		}
+	}
+	return reads, writes
+}
+
+// getOverlappingFieldPath returns the first of the supplied read field paths
+// overlapping any of the supplied written ones, i.e. equal to, nested under or
+// containing it.
+func getOverlappingFieldPath(reads, writes []string) (string, bool) {
+	for _, r := range reads {
+		if r != "" && isPatched(writes, r) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// getComposedTemplateName returns the name of the composed template at the
+// supplied index, or its path if it's anonymous.
+func getComposedTemplateName(comp *v1.Composition, i int) string {
+	if n := comp.Spec.Resources[i].Name; n != nil && *n != "" {
+		return *n
+	}
+	return field.NewPath("spec", "resources").Index(i).String()
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// PatchAndTransformInputGroupKind is the GroupKind of the input of
+// function-patch-and-transform, which embeds classic composed resource
+// templates and patches.
+var PatchAndTransformInputGroupKind = schema.GroupKind{Group: "pt.fn.crossplane.io", Kind: "Resources"}
+
+// patchAndTransformInput is the subset of the function-patch-and-transform
+// input we know how to validate. Its resources and patch sets share the schema
+// of the classic Composition ones.
+type patchAndTransformInput struct {
+	PatchSets []v1.PatchSet         `json:"patchSets,omitempty"`
+	Resources []v1.ComposedTemplate `json:"resources,omitempty"`
+}
+
+// validatePatchAndTransformInputsWithSchemas validates the resources embedded
+// in the input of any function-patch-and-transform pipeline step using the
+// same validators used for Compositions in Resources mode.
+func (v *Validator) validatePatchAndTransformInputsWithSchemas(ctx context.Context, comp *v1.Composition) (errs field.ErrorList) {
+	for i, step := range comp.Spec.Pipeline {
+		in, ok := getPatchAndTransformInput(step.Input)
+		if !ok {
+			continue
+		}
+		// Validate the input as if it was a Composition in Resources mode,
+		// then move any error below the pipeline step it belongs to.
+		c := &v1.Composition{
+			ObjectMeta: comp.ObjectMeta,
+			Spec: v1.CompositionSpec{
+				CompositeTypeRef: comp.Spec.CompositeTypeRef,
+				Mode:             ptr.To(v1.CompositionModeResources),
+				PatchSets:        in.PatchSets,
+				Resources:        in.Resources,
+			},
+		}
+		input := field.NewPath("spec", "pipeline").Index(i).Child("input")
+		for _, f := range []func(context.Context, *v1.Composition) field.ErrorList{
+			v.validatePatchesWithSchemas,
+			v.validateReadinessChecksWithSchemas,
+			v.validateConnectionDetailsWithSchemas,
+		} {
+			for _, err := range f(ctx, c) {
+				err.Field = strings.Replace(err.Field, "spec", input.String(), 1)
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// PatchAndTransformInputResources returns the resource templates embedded in
+// the input of any function-patch-and-transform pipeline step of the supplied
+// Composition.
+func PatchAndTransformInputResources(comp *v1.Composition) []v1.ComposedTemplate {
+	var out []v1.ComposedTemplate
+	for _, step := range comp.Spec.Pipeline {
+		if in, ok := getPatchAndTransformInput(step.Input); ok {
+			out = append(out, in.Resources...)
+		}
+	}
+	return out
+}
+
+// getPatchAndTransformInput returns the function-patch-and-transform input
+// held by the supplied raw input, if any.
+func getPatchAndTransformInput(raw *runtime.RawExtension) (*patchAndTransformInput, bool) {
+	if raw == nil || len(raw.Raw) == 0 {
+		return nil, false
+	}
+	tm := &runtime.TypeMeta{}
+	if err := json.Unmarshal(raw.Raw, tm); err != nil {
+		return nil, false
+	}
+	if tm.GroupVersionKind().GroupKind() != PatchAndTransformInputGroupKind {
+		return nil, false
+	}
+	// Inputs that don't decode are left to the function to reject, we only
+	// validate the ones we can make sense of.
+	in := &patchAndTransformInput{}
+	if err := json.Unmarshal(raw.Raw, in); err != nil {
+		return nil, false
+	}
+	return in, true
+}
+
+// validateFunctionInputsWithSchemas validates the input of each pipeline step
+// against the schema of its kind. Functions may publish the schema of their
+// input as a CRD, e.g. as part of their package, inputs whose schema isn't
+// available are not validated.
+func (v *Validator) validateFunctionInputsWithSchemas(ctx context.Context, comp *v1.Composition) (errs field.ErrorList) {
+	for i, step := range comp.Spec.Pipeline {
+		if step.Input == nil || len(step.Input.Raw) == 0 {
+			continue
+		}
+		in := map[string]any{}
+		if err := json.Unmarshal(step.Input.Raw, &in); err != nil {
+			continue
+		}
+		tm := &runtime.TypeMeta{}
+		if err := json.Unmarshal(step.Input.Raw, tm); err != nil {
+			continue
+		}
+		gvk := tm.GroupVersionKind()
+		crd, err := v.crdGetter.Get(ctx, gvk.GroupKind())
+		if err != nil || crd == nil {
+			continue
+		}
+		s := getSchemaForVersion(crd, gvk.Version)
+		if s == nil {
+			continue
+		}
+		path := field.NewPath("spec", "pipeline").Index(i).Child("input")
+		sv, _, err := validation.NewSchemaValidator(s)
+		if err != nil {
+			errs = append(errs, field.InternalError(path, err))
+			continue
+		}
+		errs = append(errs, validation.ValidateCustomResource(path, in, sv)...)
+	}
+	return errs
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// validateComposedNamespaces returns a warning for each composed resource
+// whose base sets a namespace, while its CRD says it's cluster scoped.
+func (v *Validator) validateComposedNamespaces(ctx context.Context, comp *v1.Composition) (warns []string) {
+	for i := range comp.Spec.Resources {
+		obj, err := GetBaseObject(&comp.Spec.Resources[i])
+		if err != nil {
+			// Invalid bases are reported by the logical validation.
+			continue
+		}
+		ns := obj.GetNamespace()
+		if ns == "" {
+			continue
+		}
+		gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+		// Errors getting CRDs are already reported by validatePatchesWithSchemas.
+		crd, err := v.crdGetter.Get(ctx, gk)
+		if err != nil || crd == nil || crd.Spec.Scope != apiextensions.ClusterScoped {
+			continue
+		}
+		warns = append(warns, fmt.Sprintf("%s: composed resource %s is cluster scoped, the namespace %q set by its base will be ignored", field.NewPath("spec", "resources").Index(i).Child("base", "metadata", "namespace"), gk, ns))
+	}
+	return warns
+}
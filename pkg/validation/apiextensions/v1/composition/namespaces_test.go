@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// nilCRDGetter is a CRDGetter that finds no CRD, without returning an error.
+type nilCRDGetter struct{}
+
+func (nilCRDGetter) Get(_ context.Context, _ schema.GroupKind) (*apiextensions.CustomResourceDefinition, error) {
+	return nil, nil
+}
+
+func (nilCRDGetter) GetAll(_ context.Context) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, error) {
+	return nil, nil
+}
+
+func TestValidateComposedNamespaces(t *testing.T) {
+	type args struct {
+		comp      *v1.Composition
+		crdGetter CRDGetter
+	}
+	type want struct {
+		warns []string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClusterScoped": {
+			reason: "Should warn about a namespace set in the base of a cluster scoped composed resource.",
+			args: args{
+				comp:      buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil),
+				crdGetter: crdGetterMap(clusterScopedManagedGKToCRDs()),
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[0].base.metadata.namespace: composed resource Managed.resources.test.com is cluster scoped, the namespace "testns" set by its base will be ignored`,
+				},
+			},
+		},
+		"NamespaceScoped": {
+			reason: "Should not warn about a namespace set in the base of a namespaced composed resource.",
+			args: args{
+				comp:      buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil),
+				crdGetter: crdGetterMap(defaultGKToCRDs()),
+			},
+		},
+		"MissingCRD": {
+			reason: "Should not warn if the CRD of the composed resource can't be found.",
+			args: args{
+				comp:      buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil),
+				crdGetter: crdGetterMap(nil),
+			},
+		},
+		"NilCRD": {
+			reason: "Should not warn, nor panic, if the CRD getter returns no CRD and no error.",
+			args: args{
+				comp:      buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil),
+				crdGetter: nilCRDGetter{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(WithCRDGetter(tc.args.crdGetter))
+			if err != nil {
+				t.Fatalf("NewValidator(...) = %v", err)
+			}
+			got := v.validateComposedNamespaces(context.TODO(), tc.args.comp)
+			if diff := cmp.Diff(tc.want.warns, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nvalidateComposedNamespaces(...) = -want warnings, +got warnings\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -20,6 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -39,10 +42,20 @@ import (
 
 const (
 	errFmtArrayIndexAboveMax   = "index is above the allowed size of the array: %d > %d"
+	errFmtArrayAppend          = "index '%s' is not supported, field paths can't append to arrays"
+	errFmtArrayIndexNoSchema   = "no schema for item requested at index %d"
 	errFmtFieldInvalid         = "field '%s' is not valid according to the schema"
 	errFmtIndexAccessWrongType = "trying to access a '%s' by index"
 	errFmtFieldAccessWrongType = "trying to access a field '%s' of object, but schema says parent is of type: '%v'"
 	errUnableToParse           = "cannot parse base"
+
+	errFmtMergeOptionsWrongType = "merge options can only be used if the toFieldPath is an array or an object, but it is of type %s"
+
+	errFmtCombineVariableNotScalar = "string combine variables must be scalars, but it is of type %s"
+
+	errMapTransformNoPairs       = "map transform must have at least one pair"
+	errFmtMapTransformPairValue  = "cannot parse value of map transform pair %q"
+	errFmtMapTransformMixedTypes = "map transform values must all have the same type, value of pair %q is of type %s, expected %s"
 )
 
 // validatePatchesWithSchemas validates the patches of a composition against the resources schemas.
@@ -84,10 +97,12 @@ func (v *Validator) validateEnvironmentPatchesWithSchemas(ctx context.Context, c
 			continue
 		}
 		if err := verrors.WrapFieldError(v.validatePatchWithSchemaInternal(patchValidationCtx{
-			comp:            comp,
-			patch:           *v1Patch,
-			compositeCRD:    compositeCRD,
-			compositeResGVK: compositeResGVK,
+			comp:               comp,
+			patch:              *v1Patch,
+			compositeCRD:       compositeCRD,
+			compositeResGVK:    compositeResGVK,
+			environmentSchema:  v.environmentSchema,
+			patchesEnvironment: true,
 		}), field.NewPath("spec").Child("environment", "patches").Index(i)); err != nil {
 			errs = append(errs, err)
 		}
@@ -150,12 +165,13 @@ func (v *Validator) validatePatchWithSchemas(ctx context.Context, comp *v1.Compo
 	}
 
 	return verrors.WrapFieldError(v.validatePatchWithSchemaInternal(patchValidationCtx{
-		comp:            comp,
-		patch:           patch,
-		compositeCRD:    compositeCRD,
-		compositeResGVK: compositeResGVK,
-		resourceCRD:     resourceCRD,
-		resourceGVK:     resourceGVK,
+		comp:              comp,
+		patch:             patch,
+		compositeCRD:      compositeCRD,
+		compositeResGVK:   compositeResGVK,
+		resourceCRD:       resourceCRD,
+		resourceGVK:       resourceGVK,
+		environmentSchema: v.environmentSchema,
 	}), field.NewPath("spec").Child("resources").Index(resourceNumber).Child("patches").Index(patchNumber))
 }
 
@@ -166,6 +182,23 @@ type patchValidationCtx struct {
 	compositeResGVK schema.GroupVersionKind
 	resourceCRD     *apiextensions.CustomResourceDefinition
 	resourceGVK     schema.GroupVersionKind
+
+	// environmentSchema is the schema of the environment, if known.
+	environmentSchema *apiextensions.JSONSchemaProps
+
+	// patchesEnvironment is true for the patches of the environment itself,
+	// which patch the environment rather than a composed resource.
+	patchesEnvironment bool
+}
+
+// getResourceSchema returns the schema of what the patch being validated
+// patches along with the composite resource, either a composed resource or,
+// for the patches of the environment itself, the environment.
+func (ctx patchValidationCtx) getResourceSchema() *apiextensions.JSONSchemaProps {
+	if ctx.patchesEnvironment {
+		return ctx.environmentSchema
+	}
+	return getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version)
 }
 
 func (v *Validator) validatePatchWithSchemaInternal(ctx patchValidationCtx) *field.Error {
@@ -176,24 +209,26 @@ func (v *Validator) validatePatchWithSchemaInternal(ctx patchValidationCtx) *fie
 		fromType, toType, validationErr = validateFromCompositeFieldPathPatch(
 			ctx.patch,
 			getSchemaForVersion(ctx.compositeCRD, ctx.compositeResGVK.Version),
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.getResourceSchema(),
 		)
 	case v1.PatchTypeToCompositeFieldPath:
 		fromType, toType, validationErr = validateFromCompositeFieldPathPatch(
 			ctx.patch,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.getResourceSchema(),
 			getSchemaForVersion(ctx.compositeCRD, ctx.compositeResGVK.Version),
 		)
 	case v1.PatchTypeCombineFromComposite:
 		fromType, toType, validationErr = validateCombineFromCompositePathPatch(
 			ctx.patch,
 			getSchemaForVersion(ctx.compositeCRD, ctx.compositeResGVK.Version),
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.getResourceSchema(),
 		)
 	case v1.PatchTypeCombineToComposite:
+		// Variables are read from the composed resource, and the result is
+		// written to the composite resource.
 		fromType, toType, validationErr = validateCombineFromCompositePathPatch(
 			ctx.patch,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.getResourceSchema(),
 			getSchemaForVersion(ctx.compositeCRD, ctx.compositeResGVK.Version),
 		)
 	case v1.PatchTypePatchSet:
@@ -201,15 +236,9 @@ func (v *Validator) validatePatchWithSchemaInternal(ctx patchValidationCtx) *fie
 		for i, ps := range ctx.comp.Spec.PatchSets {
 			if *ctx.patch.PatchSetName == ps.Name {
 				for j, patch := range ps.Patches {
-					if err := v.validatePatchWithSchemaInternal(patchValidationCtx{
-						comp:            ctx.comp,
-						patch:           patch,
-						compositeCRD:    ctx.compositeCRD,
-						compositeResGVK: ctx.compositeResGVK,
-						resourceCRD:     ctx.resourceCRD,
-						resourceGVK:     ctx.resourceGVK,
-					},
-					); err != nil {
+					psCtx := ctx
+					psCtx.patch = patch
+					if err := v.validatePatchWithSchemaInternal(psCtx); err != nil {
 						return verrors.WrapFieldError(err, field.NewPath("patchSets").Index(i).Child("patches").Index(j))
 					}
 				}
@@ -218,34 +247,141 @@ func (v *Validator) validatePatchWithSchemaInternal(ctx patchValidationCtx) *fie
 	case v1.PatchTypeFromEnvironmentFieldPath:
 		fromType, toType, validationErr = validateFromCompositeFieldPathPatch(
 			ctx.patch,
-			nil,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.environmentSchema,
+			ctx.getResourceSchema(),
 		)
 	case v1.PatchTypeToEnvironmentFieldPath:
 		fromType, toType, validationErr = validateFromCompositeFieldPathPatch(
 			ctx.patch,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
-			nil,
+			ctx.getResourceSchema(),
+			ctx.environmentSchema,
 		)
 	case v1.PatchTypeCombineFromEnvironment:
 		fromType, toType, validationErr = validateCombineFromCompositePathPatch(
 			ctx.patch,
-			nil,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
+			ctx.environmentSchema,
+			ctx.getResourceSchema(),
 		)
 	case v1.PatchTypeCombineToEnvironment:
 		fromType, toType, validationErr = validateCombineFromCompositePathPatch(
 			ctx.patch,
-			getSchemaForVersion(ctx.resourceCRD, ctx.resourceGVK.Version),
-			nil,
+			ctx.getResourceSchema(),
+			ctx.environmentSchema,
 		)
 	}
 	if validationErr != nil {
-		return validationErr
+		return withServedVersionsHint(ctx, validationErr)
+	}
+	if err := validateMergeOptions(ctx.patch, toType); err != nil {
+		return err
 	}
 	return validateIOTypesWithTransforms(ctx.patch.Transforms, fromType, toType)
 }
 
+// validateMergeOptions validates the merge options of the supplied patch, if
+// any, are only set for a toFieldPath of a type they apply to, i.e. an array
+// or an object.
+func validateMergeOptions(patch v1.Patch, toType xpschema.KnownJSONType) *field.Error {
+	if patch.Policy == nil || patch.Policy.MergeOptions == nil {
+		return nil
+	}
+	switch toType {
+	case "", xpschema.KnownJSONTypeArray, xpschema.KnownJSONTypeObject:
+		return nil
+	}
+	return field.Invalid(field.NewPath("policy", "mergeOptions"), patch.Policy.MergeOptions, fmt.Sprintf(errFmtMergeOptionsWrongType, toType))
+}
+
+// withServedVersionsHint returns a copy of the supplied error with a hint
+// appended if it's about a field path not valid for the targeted version of a
+// CRD, but valid for another version it serves. This is usually the case for
+// fields added or removed across versions, e.g. while migrating to a new one.
+func withServedVersionsHint(ctx patchValidationCtx, err *field.Error) *field.Error {
+	var fieldPath string
+	var crd *apiextensions.CustomResourceDefinition
+	var gvk schema.GroupVersionKind
+	switch err.Field {
+	case "fromFieldPath":
+		fieldPath = ctx.patch.GetFromFieldPath()
+		switch ctx.patch.GetType() { //nolint:exhaustive // Only these patch types read from a CRD through fromFieldPath.
+		case v1.PatchTypeFromCompositeFieldPath:
+			crd, gvk = ctx.compositeCRD, ctx.compositeResGVK
+		case v1.PatchTypeToCompositeFieldPath, v1.PatchTypeToEnvironmentFieldPath:
+			crd, gvk = ctx.resourceCRD, ctx.resourceGVK
+		}
+	case "toFieldPath":
+		fieldPath = ctx.patch.GetToFieldPath()
+		switch ctx.patch.GetType() { //nolint:exhaustive // Only these patch types write to a CRD through toFieldPath.
+		case v1.PatchTypeFromCompositeFieldPath, v1.PatchTypeCombineFromComposite, v1.PatchTypeFromEnvironmentFieldPath, v1.PatchTypeCombineFromEnvironment:
+			crd, gvk = ctx.resourceCRD, ctx.resourceGVK
+		case v1.PatchTypeToCompositeFieldPath, v1.PatchTypeCombineToComposite:
+			crd, gvk = ctx.compositeCRD, ctx.compositeResGVK
+		}
+	case "combine":
+		return withCombineVariablesServedVersionsHint(ctx, err)
+	}
+	versions := getOtherServedVersionsWithFieldPath(crd, gvk.Version, fieldPath)
+	if len(versions) == 0 {
+		return err
+	}
+	out := *err
+	out.Detail = fmt.Sprintf("%s, but it's valid for served version(s) %s of %s, while version %s is targeted", out.Detail, strings.Join(versions, ", "), gvk.Kind, gvk.Version)
+	return &out
+}
+
+// withCombineVariablesServedVersionsHint returns a copy of the supplied error
+// about the variables of a combine patch with a hint appended for each
+// variable not valid for the targeted version of a CRD, but valid for another
+// version it serves.
+func withCombineVariablesServedVersionsHint(ctx patchValidationCtx, err *field.Error) *field.Error {
+	var crd *apiextensions.CustomResourceDefinition
+	var gvk schema.GroupVersionKind
+	switch ctx.patch.GetType() { //nolint:exhaustive // Only these patch types read combine variables from a CRD.
+	case v1.PatchTypeCombineFromComposite:
+		crd, gvk = ctx.compositeCRD, ctx.compositeResGVK
+	case v1.PatchTypeCombineToComposite, v1.PatchTypeCombineToEnvironment:
+		crd, gvk = ctx.resourceCRD, ctx.resourceGVK
+	}
+	if crd == nil || ctx.patch.Combine == nil {
+		return err
+	}
+	s := getSchemaForVersion(crd, gvk.Version)
+	var hints []string
+	for _, variable := range ctx.patch.Combine.Variables {
+		if _, verr := validateFieldPath(s, variable.FromFieldPath); verr == nil {
+			continue
+		}
+		if versions := getOtherServedVersionsWithFieldPath(crd, gvk.Version, variable.FromFieldPath); len(versions) > 0 {
+			hints = append(hints, fmt.Sprintf("%s is valid for served version(s) %s", variable.FromFieldPath, strings.Join(versions, ", ")))
+		}
+	}
+	if len(hints) == 0 {
+		return err
+	}
+	out := *err
+	out.Detail = fmt.Sprintf("%s, but %s of %s, while version %s is targeted", out.Detail, strings.Join(hints, ", "), gvk.Kind, gvk.Version)
+	return &out
+}
+
+// getOtherServedVersionsWithFieldPath returns the versions served by the given
+// CRD, other than the given one, for which the given field path is valid.
+func getOtherServedVersionsWithFieldPath(crd *apiextensions.CustomResourceDefinition, version, fieldPath string) []string {
+	// A top-level schema is shared by all versions.
+	if crd == nil || crd.Spec.Validation != nil || fieldPath == "" {
+		return nil
+	}
+	var versions []string
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version || !v.Served || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		if _, err := validateFieldPath(v.Schema.OpenAPIV3Schema, fieldPath); err == nil {
+			versions = append(versions, v.Name)
+		}
+	}
+	return versions
+}
+
 // validateCombineFromCompositePathPatch validates Combine Patch types, by going through and validating the fromField
 // path variables, checking if the right combine strategy is set and validating transforms.
 func validateCombineFromCompositePathPatch(patch v1.Patch, from, to *apiextensions.JSONSchemaProps) (fromType, toType xpschema.KnownJSONType, err *field.Error) {
@@ -257,11 +393,16 @@ func validateCombineFromCompositePathPatch(patch v1.Patch, from, to *apiextensio
 	errs := field.ErrorList{}
 	for _, variable := range patch.Combine.Variables {
 		fromFieldPath := variable.FromFieldPath
-		_, err := validateFieldPath(from, fromFieldPath)
+		varType, err := validateFieldPath(from, fromFieldPath)
 		if err != nil {
 			errs = append(errs, field.Invalid(field.NewPath("fromFieldPath"), fromFieldPath, err.Error()))
 			continue
 		}
+		// Objects and arrays would be formatted as Go values by a string
+		// combine, which is never what's intended.
+		if patch.Combine.Strategy == v1.CombineStrategyString && (varType == xpschema.KnownJSONTypeObject || varType == xpschema.KnownJSONTypeArray) {
+			errs = append(errs, field.Invalid(field.NewPath("fromFieldPath"), fromFieldPath, fmt.Sprintf(errFmtCombineVariableNotScalar, varType)))
+		}
 	}
 
 	if len(errs) > 0 {
@@ -278,11 +419,125 @@ func validateCombineFromCompositePathPatch(patch v1.Patch, from, to *apiextensio
 		return "", "", field.Invalid(field.NewPath("combine", "strategy"), patch.Combine.Strategy, "combine strategy is not supported")
 	}
 
-	// TODO(lsviben): check if we could validate the patch combine format, worth looking at https://cs.opensource.google/go/x/tools/+/refs/tags/v0.7.0:go/analysis/passes/printf/printf.go;l=1025
+	if err := patch.Combine.Validate(); err != nil {
+		return "", "", verrors.WrapFieldError(err, field.NewPath("combine"))
+	}
 
 	return fromType, toType, nil
 }
 
+// validateCombinePatchesOptionalVariables returns a warning for each string
+// combine patch having variables that are not guaranteed to be set according
+// to the source schema. At runtime, a combine patch is not applied at all if
+// any of its variables is unset, which authors often don't expect.
+func (v *Validator) validateCombinePatchesOptionalVariables(ctx context.Context, comp *v1.Composition) (warns []string) {
+	compositeResGVK := schema.FromAPIVersionAndKind(
+		comp.Spec.CompositeTypeRef.APIVersion,
+		comp.Spec.CompositeTypeRef.Kind,
+	)
+	var compositeSchema *apiextensions.JSONSchemaProps
+	// Errors getting CRDs are already reported by validatePatchesWithSchemas.
+	if crd, err := v.crdGetter.Get(ctx, compositeResGVK.GroupKind()); err == nil {
+		compositeSchema = getSchemaForVersion(crd, compositeResGVK.Version)
+	}
+
+	for i := range comp.Spec.Resources {
+		resource := comp.Spec.Resources[i]
+		var resourceSchema *apiextensions.JSONSchemaProps
+		if gvk, err := GetBaseObjectGVK(&resource); err == nil {
+			if crd, err := v.crdGetter.Get(ctx, gvk.GroupKind()); err == nil {
+				resourceSchema = getSchemaForVersion(crd, gvk.Version)
+			}
+		}
+
+		for j, p := range resource.Patches {
+			patches := []v1.Patch{p}
+			if p.GetType() == v1.PatchTypePatchSet {
+				patches = getPatchSetPatches(comp, p.PatchSetName)
+			}
+			for _, p := range patches {
+				var from *apiextensions.JSONSchemaProps
+				switch p.GetType() { //nolint:exhaustive // Only combine patches are relevant here.
+				case v1.PatchTypeCombineFromComposite:
+					from = compositeSchema
+				case v1.PatchTypeCombineToComposite:
+					from = resourceSchema
+				}
+				if optional := getOptionalCombineVariables(p, from); len(optional) > 0 {
+					warns = append(warns, fmt.Sprintf("%s: string combine patch uses variables not required by the schema %v, the patch won't be applied if any of them is unset; consider making them required or setting defaults", field.NewPath("spec", "resources").Index(i).Child("patches").Index(j), optional))
+				}
+			}
+		}
+	}
+	return warns
+}
+
+// getPatchSetPatches returns the patches of the named patch set, if any.
+func getPatchSetPatches(comp *v1.Composition, name *string) []v1.Patch {
+	if name == nil {
+		return nil
+	}
+	for _, ps := range comp.Spec.PatchSets {
+		if ps.Name == *name {
+			return ps.Patches
+		}
+	}
+	return nil
+}
+
+// getOptionalCombineVariables returns the fromFieldPath of all the variables of
+// the given string combine patch not guaranteed to be set according to the
+// given schema.
+func getOptionalCombineVariables(patch v1.Patch, from *apiextensions.JSONSchemaProps) (optional []string) {
+	if from == nil || patch.Combine == nil || patch.Combine.Strategy != v1.CombineStrategyString {
+		return nil
+	}
+	// A required policy makes the patch fail instead of being silently skipped.
+	if patch.Policy != nil && ptr.Deref(patch.Policy.FromFieldPath, v1.FromFieldPathPolicyOptional) == v1.FromFieldPathPolicyRequired {
+		return nil
+	}
+	for _, variable := range patch.Combine.Variables {
+		if !isRequiredFieldPath(from, variable.FromFieldPath) {
+			optional = append(optional, variable.FromFieldPath)
+		}
+	}
+	return optional
+}
+
+// isRequiredFieldPath returns true if the given field path is guaranteed to be
+// set according to the given schema, i.e. each segment is either required or
+// has a default.
+func isRequiredFieldPath(schema *apiextensions.JSONSchemaProps, fieldPath string) bool {
+	switch fieldPath {
+	case "metadata.name", "metadata.uid":
+		return true
+	}
+	segments, err := fieldpath.Parse(fieldPath)
+	if err != nil {
+		return false
+	}
+	current := schema
+	for _, segment := range segments {
+		if current == nil {
+			return false
+		}
+		switch segment.Type {
+		case fieldpath.SegmentField:
+			prop, exists := current.Properties[segment.Field]
+			if !exists || (!slices.Contains(current.Required, segment.Field) && prop.Default == nil) {
+				return false
+			}
+			current = &prop
+		case fieldpath.SegmentIndex:
+			if current.MinItems == nil || *current.MinItems <= int64(segment.Index) || current.Items == nil {
+				return false
+			}
+			current = current.Items.Schema
+		}
+	}
+	return true
+}
+
 // validateFromCompositeFieldPathPatch validates a patch of type FromCompositeFieldPath.
 func validateFromCompositeFieldPathPatch(patch v1.Patch, from, to *apiextensions.JSONSchemaProps) (fromType, toType xpschema.KnownJSONType, res *field.Error) {
 	fromFieldPath := patch.GetFromFieldPath()
@@ -336,6 +591,12 @@ func validateTransformsChainIOTypes(transforms []v1.Transform, fromType xpschema
 		if err != nil {
 			return "", field.InternalError(field.NewPath("transforms").Index(i), err)
 		}
+		if transform.Type == v1.TransformTypeMap {
+			out, err = GetMapTransformOutputType(transform.Map)
+			if err != nil {
+				return "", field.Invalid(field.NewPath("transforms").Index(i), transform, err.Error())
+			}
+		}
 		if out == nil {
 			// no need to validate the rest of the transforms as a nil output without error means we don't
 			// have a way to know the output type for some transforms
@@ -380,6 +641,11 @@ func validateFieldPathSegments(segments fieldpath.Segments, schema *apiextension
 		current = currentSegment
 	}
 
+	// Fields like Kubernetes quantities accept both integers and strings, and
+	// have no type set.
+	if current.XIntOrString && current.Type == "" {
+		return xpschema.KnownJSONTypeIntOrString, nil
+	}
 	if !xpschema.IsValid(current.Type) {
 		return "", fmt.Errorf("field path %q has an unsupported type %q", fieldPath, current.Type)
 	}
@@ -405,12 +671,23 @@ func validateFieldPathSegmentField(parent *apiextensions.JSONSchemaProps, segmen
 	if segment.Type != fieldpath.SegmentField {
 		return nil, errors.Errorf("segment is not a field")
 	}
+	// Negative indexes, e.g. items[-1], are parsed as fields, and would be
+	// rejected below as a field access on an array. Crossplane doesn't support
+	// appending to arrays through field paths, so say that explicitly.
+	if parent.Type == string(xpschema.KnownJSONTypeArray) && strings.HasPrefix(segment.Field, "-") {
+		if _, err := strconv.Atoi(segment.Field); err == nil {
+			return nil, errors.Errorf(errFmtArrayAppend, segment.Field)
+		}
+	}
 	if propType := parent.Type; propType != "" && propType != string(xpschema.KnownJSONTypeObject) {
 		return nil, errors.Errorf(errFmtFieldAccessWrongType, segment.Field, propType)
 	}
 	// TODO(phisco): any remaining fields? e.g. XValidations' CEL Rules?
 	prop, exists := parent.Properties[segment.Field]
 	if !exists {
+		// Only unknown fields are accepted as is if the parent preserves
+		// unknown fields, declared ones are still validated against their
+		// schema.
 		if ptr.Deref(parent.XPreserveUnknownFields, false) {
 			return nil, nil
 		}
@@ -451,12 +728,83 @@ func validateFieldPathSegmentIndex(parent *apiextensions.JSONSchemaProps, segmen
 		return s, nil
 	}
 	schemas := parent.Items.JSONSchemas
-	if len(schemas) < int(segment.Index) {
-		return nil, errors.Errorf("no schema for item requested at index %d", segment.Index)
+	if len(schemas) == 0 {
+		// means there is no schema at all for this array
+		return nil, nil
+	}
+	if len(schemas) <= int(segment.Index) {
+		return nil, errors.Errorf(errFmtArrayIndexNoSchema, segment.Index)
 	}
+	return &schemas[segment.Index], nil
+}
 
-	// means there is no schema at all for this array
-	return nil, nil
+// GetMapTransformOutputType infers the output type of the supplied map
+// transform from the values of its pairs, which must all have the same JSON
+// type. Integers are widened to float64 if mixed with non-integer numbers.
+// It returns nil if the output type can't be known, e.g. all values are null.
+func GetMapTransformOutputType(m *v1.MapTransform) (*v1.TransformIOType, error) {
+	if m == nil || len(m.Pairs) == 0 {
+		return nil, errors.New(errMapTransformNoPairs)
+	}
+	var out v1.TransformIOType
+	// iterate in a stable order so that errors are deterministic
+	keys := make([]string, 0, len(m.Pairs))
+	for k := range m.Pairs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		t, err := getJSONValueIOType(m.Pairs[k].Raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtMapTransformPairValue, k)
+		}
+		switch {
+		case t == "":
+			// null values are valid for any output type
+		case out == "" || out == t:
+			out = t
+		case isNumericIOType(out) && isNumericIOType(t):
+			out = v1.TransformIOTypeFloat64
+		default:
+			return nil, errors.Errorf(errFmtMapTransformMixedTypes, k, t, out)
+		}
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return &out, nil
+}
+
+func isNumericIOType(t v1.TransformIOType) bool {
+	return t == v1.TransformIOTypeInt64 || t == v1.TransformIOTypeFloat64
+}
+
+// getJSONValueIOType returns the TransformIOType of the supplied raw JSON
+// value, or an empty string if it is null.
+func getJSONValueIOType(raw []byte) (v1.TransformIOType, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v1.TransformIOTypeString, nil
+	case bool:
+		return v1.TransformIOTypeBool, nil
+	case float64:
+		if val == float64(int64(val)) {
+			return v1.TransformIOTypeInt64, nil
+		}
+		return v1.TransformIOTypeFloat64, nil
+	case map[string]any:
+		return v1.TransformIOTypeObject, nil
+	case []any:
+		return v1.TransformIOTypeArray, nil
+	}
+	// should never happen
+	return "", errors.Errorf("unknown JSON value type %T", v)
 }
 
 // IsValidInputForTransform validates the supplied Transform type, taking into consideration also the input type.
@@ -481,7 +829,18 @@ func IsValidInputForTransform(t *v1.Transform, fromType v1.TransformIOType) erro
 				return errors.Errorf("string transform can only be used with string input types, got %s", fromType)
 			}
 		case v1.StringTransformTypeFormat:
-			// any input type is valid
+			if t.String.Format == nil {
+				return nil
+			}
+			verbs, ok := v1.GetFormatVerbs(*t.String.Format)
+			if !ok {
+				return nil
+			}
+			for _, verb := range verbs {
+				if !isFormatVerbCompatible(verb, fromType) {
+					return errors.Errorf("string transform format verb %%%c cannot be used with %s input types", verb, fromType)
+				}
+			}
 		case v1.StringTransformTypeConvert:
 			if t.String.Convert == nil {
 				return errors.Errorf("string transform convert type is required for convert transform")
@@ -513,6 +872,37 @@ func IsValidInputForTransform(t *v1.Transform, fromType v1.TransformIOType) erro
 	return nil
 }
 
+// isFormatVerbCompatible returns true if the supplied Go format verb can
+// format values of the supplied type. Arrays and objects are formatted element
+// by element, so their compatibility depends on the type of their elements,
+// which is not known.
+func isFormatVerbCompatible(verb rune, t v1.TransformIOType) bool {
+	if t == v1.TransformIOTypeArray || t == v1.TransformIOTypeObject {
+		return true
+	}
+	switch verb {
+	case 'v', 'T':
+		return true
+	case 's':
+		return t == v1.TransformIOTypeString
+	case 'q':
+		// Integers are formatted as quoted character literals.
+		return t == v1.TransformIOTypeString || t == v1.TransformIOTypeInt || t == v1.TransformIOTypeInt64
+	case 'x', 'X':
+		return t == v1.TransformIOTypeString || t == v1.TransformIOTypeInt || isNumericIOType(t)
+	case 'b':
+		// Floats are formatted in binary scientific notation.
+		return t == v1.TransformIOTypeInt || isNumericIOType(t)
+	case 'd', 'o', 'O', 'c', 'U':
+		return t == v1.TransformIOTypeInt || t == v1.TransformIOTypeInt64
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return t == v1.TransformIOTypeFloat64
+	case 't':
+		return t == v1.TransformIOTypeBool
+	}
+	return false
+}
+
 // GetBaseObject returns the base object of the composed template.
 // Uses the cached object if it is available, or parses the raw Base
 // otherwise. The returned object is a deep copy.
@@ -17,6 +17,7 @@ limitations under the License.
 package composition
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -27,10 +28,12 @@ import (
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xperrors "github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -116,6 +119,39 @@ func TestValidateTransforms(t *testing.T) {
 				toType:     "integer",
 			},
 		},
+		"AcceptFormatStringTransformCompatibleVerb": {
+			reason: "Should accept a format string transform whose verbs are compatible with the input type",
+			want:   want{err: nil},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("replicas-%d"),
+					},
+				}},
+				fromType: "integer",
+				toType:   "string",
+			},
+		},
+		"RejectFormatStringTransformIncompatibleVerb": {
+			reason: "Should reject a format string transform whose verbs are not compatible with the input type",
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "transforms[0]",
+			}},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("replicas-%d"),
+					},
+				}},
+				fromType: "string",
+				toType:   "string",
+			},
+		},
 		"AcceptEmptyTransformsCompatibleTypes": {
 			reason: "Should accept empty transforms to a different type when its integer to number",
 			want:   want{err: nil},
@@ -330,6 +366,137 @@ func TestValidateTransforms(t *testing.T) {
 				toType:   "string",
 			},
 		},
+		"AcceptConvertQuantityToIntOrString": {
+			reason: "Should accept a quantity converted to an integer into an int-or-string field",
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeConvert,
+					Convert: &v1.ConvertTransform{
+						ToType: v1.TransformIOTypeFloat64,
+						Format: ptr.To(v1.ConvertTransformFormatQuantity),
+					},
+				}, {
+					Type: v1.TransformTypeConvert,
+					Convert: &v1.ConvertTransform{
+						ToType: v1.TransformIOTypeInt64,
+					},
+				}},
+				fromType: "string",
+				toType:   "int-or-string",
+			},
+		},
+		"RejectConvertQuantityToIntOrString": {
+			reason: "Should reject a quantity converted to a float64 into an int-or-string field",
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "transforms",
+			}},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeConvert,
+					Convert: &v1.ConvertTransform{
+						ToType: v1.TransformIOTypeFloat64,
+						Format: ptr.To(v1.ConvertTransformFormatQuantity),
+					},
+				}},
+				fromType: "string",
+				toType:   "int-or-string",
+			},
+		},
+		"AcceptMapTransformSameType": {
+			reason: "Should accept a map transform whose values all match the type of the toFieldPath",
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeMap,
+					Map: &v1.MapTransform{
+						Pairs: map[string]extv1.JSON{
+							"small": {Raw: []byte(`1`)},
+							"large": {Raw: []byte(`3`)},
+						},
+					},
+				}},
+				fromType: "string",
+				toType:   "integer",
+			},
+		},
+		"RejectMapTransformStringToInteger": {
+			reason: "Should reject a map transform whose values are strings patched into an integer field",
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "transforms",
+			}},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeMap,
+					Map: &v1.MapTransform{
+						Pairs: map[string]extv1.JSON{
+							"small": {Raw: []byte(`"1"`)},
+							"large": {Raw: []byte(`"3"`)},
+						},
+					},
+				}},
+				fromType: "string",
+				toType:   "integer",
+			},
+		},
+		"AcceptMapTransformIntegerToNumber": {
+			reason: "Should accept a map transform whose values are all integers patched into a number field",
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeMap,
+					Map: &v1.MapTransform{
+						Pairs: map[string]extv1.JSON{
+							"small": {Raw: []byte(`1`)},
+							"large": {Raw: []byte(`3`)},
+						},
+					},
+				}},
+				fromType: "string",
+				toType:   "number",
+			},
+		},
+		"RejectMapTransformMixedTypes": {
+			reason: "Should reject a map transform whose values have different types",
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "transforms[0]",
+			}},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeMap,
+					Map: &v1.MapTransform{
+						Pairs: map[string]extv1.JSON{
+							"small": {Raw: []byte(`1`)},
+							"large": {Raw: []byte(`"3"`)},
+						},
+					},
+				}},
+				fromType: "string",
+				toType:   "integer",
+			},
+		},
+		"RejectMapTransformEmpty": {
+			reason: "Should reject a map transform without any pair",
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "transforms[0]",
+			}},
+			args: args{
+				transforms: []v1.Transform{{
+					Type: v1.TransformTypeMap,
+					Map:  &v1.MapTransform{},
+				}},
+				fromType: "string",
+				toType:   "integer",
+			},
+		},
+		"AcceptIntOrStringToString": {
+			reason: "Should accept an int-or-string field patched into a string field with no transforms",
+			args: args{
+				fromType: "int-or-string",
+				toType:   "string",
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -341,6 +508,161 @@ func TestValidateTransforms(t *testing.T) {
 	}
 }
 
+func TestValidateMergeOptions(t *testing.T) {
+	withMergeOptions := &v1.PatchPolicy{MergeOptions: &xpv1.MergeOptions{AppendSlice: ptr.To(true)}}
+	type args struct {
+		patch  v1.Patch
+		toType schema.KnownJSONType
+	}
+	type want struct {
+		err *field.Error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AcceptNoMergeOptions": {
+			reason: "Should accept a patch without merge options to a scalar field",
+			args: args{
+				patch:  v1.Patch{Policy: &v1.PatchPolicy{}},
+				toType: schema.KnownJSONTypeString,
+			},
+		},
+		"AcceptArray": {
+			reason: "Should accept merge options for an array field",
+			args: args{
+				patch:  v1.Patch{Policy: withMergeOptions},
+				toType: schema.KnownJSONTypeArray,
+			},
+		},
+		"AcceptObject": {
+			reason: "Should accept merge options for an object field",
+			args: args{
+				patch:  v1.Patch{Policy: withMergeOptions},
+				toType: schema.KnownJSONTypeObject,
+			},
+		},
+		"AcceptUnknownType": {
+			reason: "Should accept merge options for a field whose type is unknown",
+			args: args{
+				patch: v1.Patch{Policy: withMergeOptions},
+			},
+		},
+		"RejectScalar": {
+			reason: "Should reject merge options for a scalar field",
+			args: args{
+				patch:  v1.Patch{Policy: withMergeOptions},
+				toType: schema.KnownJSONTypeInteger,
+			},
+			want: want{err: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "policy.mergeOptions",
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateMergeOptions(tc.args.patch, tc.args.toType)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.IgnoreFields(field.Error{}, "BadValue", "Detail")); diff != "" {
+				t.Errorf("\n%s\nvalidateMergeOptions(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateCombineFromCompositePathPatch(t *testing.T) {
+	sch := &apiextensions.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensions.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextensions.JSONSchemaProps{
+					"name":    {Type: "string"},
+					"count":   {Type: "integer"},
+					"labels":  {Type: "object", AdditionalProperties: &apiextensions.JSONSchemaPropsOrBool{Schema: &apiextensions.JSONSchemaProps{Type: "string"}}},
+					"aliases": {Type: "array", Items: &apiextensions.JSONSchemaPropsOrArray{Schema: &apiextensions.JSONSchemaProps{Type: "string"}}},
+				},
+			},
+		},
+	}
+	combine := func(vars ...string) v1.Patch {
+		p := v1.Patch{
+			Type: v1.PatchTypeCombineFromComposite,
+			Combine: &v1.Combine{
+				Strategy: v1.CombineStrategyString,
+				String:   &v1.StringCombine{Format: "%v-%v"},
+			},
+			ToFieldPath: ptr.To("spec.name"),
+		}
+		for _, v := range vars {
+			p.Combine.Variables = append(p.Combine.Variables, v1.CombineVariable{FromFieldPath: v})
+		}
+		return p
+	}
+
+	type args struct {
+		patch v1.Patch
+	}
+	type want struct {
+		fromType schema.KnownJSONType
+		toType   schema.KnownJSONType
+		err      *field.Error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AcceptScalarVariables": {
+			reason: "Should accept a string combine patch whose variables are all scalars",
+			args: args{
+				patch: combine("spec.name", "spec.count"),
+			},
+			want: want{
+				fromType: schema.KnownJSONTypeString,
+				toType:   schema.KnownJSONTypeString,
+			},
+		},
+		"RejectObjectVariable": {
+			reason: "Should reject a string combine patch with a variable pointing at an object, naming the variable",
+			args: args{
+				patch: combine("spec.name", "spec.labels"),
+			},
+			want: want{
+				err: field.Invalid(field.NewPath("combine"), combine("spec.name", "spec.labels").Combine.Variables, field.ErrorList{
+					field.Invalid(field.NewPath("fromFieldPath"), "spec.labels", "string combine variables must be scalars, but it is of type object"),
+				}.ToAggregate().Error()),
+			},
+		},
+		"RejectArrayVariable": {
+			reason: "Should reject a string combine patch with a variable pointing at an array, naming the variable",
+			args: args{
+				patch: combine("spec.aliases", "spec.count"),
+			},
+			want: want{
+				err: field.Invalid(field.NewPath("combine"), combine("spec.aliases", "spec.count").Combine.Variables, field.ErrorList{
+					field.Invalid(field.NewPath("fromFieldPath"), "spec.aliases", "string combine variables must be scalars, but it is of type array"),
+				}.ToAggregate().Error()),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fromType, toType, err := validateCombineFromCompositePathPatch(tc.args.patch, sch, sch)
+			if diff := cmp.Diff(tc.want.err, err); diff != "" {
+				t.Errorf("\n%s\nvalidateCombineFromCompositePathPatch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.fromType, fromType); diff != "" {
+				t.Errorf("\n%s\nvalidateCombineFromCompositePathPatch(...): -want from type, +got from type:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.toType, toType); diff != "" {
+				t.Errorf("\n%s\nvalidateCombineFromCompositePathPatch(...): -want to type, +got to type:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestValidateFieldPath(t *testing.T) {
 	type args struct {
 		schema    *apiextensions.JSONSchemaProps
@@ -375,6 +697,32 @@ func TestValidateFieldPath(t *testing.T) {
 				},
 			},
 		},
+		"AcceptIntOrStringFieldPath": {
+			reason: "Should return the int-or-string pseudo type for fields like Kubernetes quantities",
+			want:   want{err: nil, fieldType: "int-or-string"},
+			args: args{
+				fieldPath: "spec.forProvider.memory",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"memory": {
+											XIntOrString: true,
+											AnyOf: []apiextensions.JSONSchemaProps{
+												{Type: "integer"},
+												{Type: "string"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"AcceptMetadataLabelsValue": {
 			reason: "Should validate a valid field path",
 			want:   want{err: nil, fieldType: "string"},
@@ -428,6 +776,122 @@ func TestValidateFieldPath(t *testing.T) {
 				},
 			},
 		},
+		"AcceptDeclaredFieldXPreserveUnknownFields": {
+			reason: "Should validate a field declared under a parent preserving unknown fields and return its type",
+			want:   want{err: nil, fieldType: "string"},
+			args: args{
+				fieldPath: "spec.forProvider.region",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Type:     "object",
+									Required: []string{"region"},
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"region": {Type: "string"},
+										"network": {
+											Type: "object",
+											Properties: map[string]apiextensions.JSONSchemaProps{
+												"id": {Type: "integer"},
+											},
+										},
+									},
+									XPreserveUnknownFields: &[]bool{true}[0],
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"AcceptNestedDeclaredFieldXPreserveUnknownFields": {
+			reason: "Should keep walking the schema of a nested field declared under a parent preserving unknown fields",
+			want:   want{err: nil, fieldType: "integer"},
+			args: args{
+				fieldPath: "spec.forProvider.network.id",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Type:     "object",
+									Required: []string{"region"},
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"region": {Type: "string"},
+										"network": {
+											Type: "object",
+											Properties: map[string]apiextensions.JSONSchemaProps{
+												"id": {Type: "integer"},
+											},
+										},
+									},
+									XPreserveUnknownFields: &[]bool{true}[0],
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"RejectNestedUndeclaredFieldXPreserveUnknownFields": {
+			reason: "Should reject an unknown field nested in a declared field, even if an ancestor preserves unknown fields",
+			want:   want{err: xperrors.Errorf(errFmtFieldInvalid, "wrong")},
+			args: args{
+				fieldPath: "spec.forProvider.network.wrong",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Type:     "object",
+									Required: []string{"region"},
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"region": {Type: "string"},
+										"network": {
+											Type: "object",
+											Properties: map[string]apiextensions.JSONSchemaProps{
+												"id": {Type: "integer"},
+											},
+										},
+									},
+									XPreserveUnknownFields: &[]bool{true}[0],
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"AcceptUnknownSiblingFieldXPreserveUnknownFields": {
+			reason: "Should accept an unknown sibling of declared fields under a parent preserving unknown fields",
+			want:   want{err: nil, fieldType: ""},
+			args: args{
+				fieldPath: "spec.forProvider.unknown.nested",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Type:     "object",
+									Required: []string{"region"},
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"region": {Type: "string"},
+										"network": {
+											Type: "object",
+											Properties: map[string]apiextensions.JSONSchemaProps{
+												"id": {Type: "integer"},
+											},
+										},
+									},
+									XPreserveUnknownFields: &[]bool{true}[0],
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"AcceptValidArray": {
 			reason: "Should validate arrays properly",
 			want:   want{err: nil, fieldType: "string"},
@@ -519,6 +983,31 @@ func TestValidateFieldPath(t *testing.T) {
 				},
 			},
 		},
+		"RejectArrayAppendFieldPath": {
+			reason: "Should reject a field path appending to an array, whatever the type of its items",
+			want:   want{err: xperrors.Errorf(errFmtArrayAppend, "-1")},
+			args: args{
+				fieldPath: "spec.forProvider.tags[-1]",
+				schema: &apiextensions.JSONSchemaProps{
+					Properties: map[string]apiextensions.JSONSchemaProps{
+						"spec": {
+							Properties: map[string]apiextensions.JSONSchemaProps{
+								"forProvider": {
+									Properties: map[string]apiextensions.JSONSchemaProps{
+										"tags": {
+											Type: "array",
+											Items: &apiextensions.JSONSchemaPropsOrArray{
+												Schema: &apiextensions.JSONSchemaProps{Type: "string"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"AcceptAnnotations": {
 			want: want{err: nil, fieldType: "string"},
 			args: args{
@@ -526,6 +1015,38 @@ func TestValidateFieldPath(t *testing.T) {
 				schema:    getDefaultSchema(),
 			},
 		},
+		"AcceptAnnotationsKeyWithDotsAndSlashes": {
+			reason: "Should accept annotation keys containing dots and slashes, as the bracket syntax escapes them",
+			want:   want{err: nil, fieldType: "string"},
+			args: args{
+				fieldPath: "metadata.annotations[example.com/foo]",
+				schema:    getDefaultSchema(),
+			},
+		},
+		"AcceptQuotedAnnotationsKeyWithDots": {
+			reason: "Should accept quoted annotation keys containing dots",
+			want:   want{err: nil, fieldType: "string"},
+			args: args{
+				fieldPath: `metadata.annotations["example.com/foo"]`,
+				schema:    getDefaultSchema(),
+			},
+		},
+		"AcceptLabelsKeyWithDotsAndSlashes": {
+			reason: "Should accept label keys containing dots and slashes, as the bracket syntax escapes them",
+			want:   want{err: nil, fieldType: "string"},
+			args: args{
+				fieldPath: "metadata.labels[app.kubernetes.io/name]",
+				schema:    getDefaultSchema(),
+			},
+		},
+		"RejectAnnotationsKeyWithDotsNestedField": {
+			reason: "Should reject a field nested under an annotation with a dotted key, as annotation values are strings",
+			want:   want{err: xperrors.Errorf(errFmtFieldAccessWrongType, "name", "string"), fieldType: ""},
+			args: args{
+				fieldPath: "metadata.annotations[example.com/foo].name",
+				schema:    getDefaultSchema(),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -547,7 +1068,8 @@ func TestValidateFieldPathSegmentIndex(t *testing.T) {
 		segment fieldpath.Segment
 	}
 	type want struct {
-		err error
+		schema *apiextensions.JSONSchemaProps
+		err    error
 	}
 	cases := map[string]struct {
 		name string
@@ -583,7 +1105,7 @@ func TestValidateFieldPathSegmentIndex(t *testing.T) {
 					Index: 1,
 				},
 			},
-			want: want{err: nil},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "string"}},
 		},
 		"AcceptMinSizeArrayBelowRequired": {
 			name: "Should return no error and required if the parent is an array, accessing element below min size",
@@ -602,7 +1124,7 @@ func TestValidateFieldPathSegmentIndex(t *testing.T) {
 					Index: 1,
 				},
 			},
-			want: want{err: nil},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "string"}},
 		},
 		"AcceptMinSizeArrayAboveNotRequired": {
 			name: "Should return no error and not required if the parent is an array, accessing element above min size",
@@ -621,7 +1143,7 @@ func TestValidateFieldPathSegmentIndex(t *testing.T) {
 					Index: 3,
 				},
 			},
-			want: want{err: nil},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "string"}},
 		},
 		"AcceptIndex0MinSize1": {
 			name: "Should return no error and required if the parent is an array with min size 1 and the index is 0",
@@ -630,62 +1152,118 @@ func TestValidateFieldPathSegmentIndex(t *testing.T) {
 					Type:     "array",
 					MinItems: &[]int64{1}[0],
 					Items: &apiextensions.JSONSchemaPropsOrArray{
-						Schema: &apiextensions.JSONSchemaProps{
-							Type: "string",
+						Schema: &apiextensions.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				segment: fieldpath.Segment{
+					Type:  fieldpath.SegmentIndex,
+					Index: 0,
+				},
+			},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "string"}},
+		},
+		"RejectAboveMaxIndex": {
+			name: "Should return an error if accessing an index that is above the max items",
+			args: args{
+				parent: &apiextensions.JSONSchemaProps{
+					Type:     "array",
+					MaxItems: &[]int64{1}[0],
+					Items: &apiextensions.JSONSchemaPropsOrArray{
+						Schema: &apiextensions.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				segment: fieldpath.Segment{
+					Type:  fieldpath.SegmentIndex,
+					Index: 1,
+				},
+			},
+			want: want{err: xperrors.Errorf(errFmtArrayIndexAboveMax, 1, 0)},
+		},
+		"AcceptBelowMaxIndex": {
+			name: "Should return no error if accessing an index that is below the max items",
+			args: args{
+				parent: &apiextensions.JSONSchemaProps{
+					Type:     "array",
+					MaxItems: &[]int64{10}[0],
+					Items: &apiextensions.JSONSchemaPropsOrArray{
+						Schema: &apiextensions.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				segment: fieldpath.Segment{
+					Type:  fieldpath.SegmentIndex,
+					Index: 1,
+				},
+			},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "string"}},
+		},
+		"AcceptTupleIndexInRange": {
+			name: "Should return the schema of the item at the given index if the parent is a tuple",
+			args: args{
+				parent: &apiextensions.JSONSchemaProps{
+					Type: "array",
+					Items: &apiextensions.JSONSchemaPropsOrArray{
+						JSONSchemas: []apiextensions.JSONSchemaProps{
+							{Type: "string"},
+							{Type: "integer"},
 						},
 					},
 				},
 				segment: fieldpath.Segment{
 					Type:  fieldpath.SegmentIndex,
-					Index: 0,
+					Index: 1,
 				},
 			},
-			want: want{err: nil},
+			want: want{schema: &apiextensions.JSONSchemaProps{Type: "integer"}},
 		},
-		"RejectAboveMaxIndex": {
-			name: "Should return an error if accessing an index that is above the max items",
+		"RejectTupleIndexOutOfRange": {
+			name: "Should return an error if accessing an index beyond the items of a tuple",
 			args: args{
 				parent: &apiextensions.JSONSchemaProps{
-					Type:     "array",
-					MaxItems: &[]int64{1}[0],
+					Type: "array",
 					Items: &apiextensions.JSONSchemaPropsOrArray{
-						Schema: &apiextensions.JSONSchemaProps{
-							Type: "string",
+						JSONSchemas: []apiextensions.JSONSchemaProps{
+							{Type: "string"},
+							{Type: "integer"},
 						},
 					},
 				},
 				segment: fieldpath.Segment{
 					Type:  fieldpath.SegmentIndex,
-					Index: 1,
+					Index: 2,
 				},
 			},
-			want: want{err: xperrors.Errorf(errFmtArrayIndexAboveMax, 1, 0)},
+			want: want{err: xperrors.Errorf(errFmtArrayIndexNoSchema, 2)},
 		},
-		"AcceptBelowMaxIndex": {
-			name: "Should return no error if accessing an index that is below the max items",
+		"AcceptNoItemSchemas": {
+			name: "Should return no schema and no error if the array has no item schema at all",
 			args: args{
 				parent: &apiextensions.JSONSchemaProps{
-					Type:     "array",
-					MaxItems: &[]int64{10}[0],
-					Items: &apiextensions.JSONSchemaPropsOrArray{
-						Schema: &apiextensions.JSONSchemaProps{
-							Type: "string",
-						},
-					},
+					Type:  "array",
+					Items: &apiextensions.JSONSchemaPropsOrArray{},
 				},
 				segment: fieldpath.Segment{
 					Type:  fieldpath.SegmentIndex,
-					Index: 1,
+					Index: 2,
 				},
 			},
+			want: want{},
 		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			_, err := validateFieldPathSegmentIndex(tc.args.parent, tc.args.segment)
+			got, err := validateFieldPathSegmentIndex(tc.args.parent, tc.args.segment)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nvalidateFieldPathSegmentIndex(...): -want, +got: %s\n", tc.name, diff)
 			}
+			if diff := cmp.Diff(tc.want.schema, got); diff != "" {
+				t.Errorf("\n%s\nvalidateFieldPathSegmentIndex(...): -want schema, +got schema: %s\n", tc.name, diff)
+			}
 		})
 	}
 }
@@ -773,6 +1351,22 @@ func TestValidateFieldPathSegmentField(t *testing.T) {
 			},
 			want: want{err: nil},
 		},
+		"RejectArrayAppend": {
+			name: "Should return an error if a negative index is used to append to an array",
+			args: args{
+				parent: &apiextensions.JSONSchemaProps{
+					Type: "array",
+					Items: &apiextensions.JSONSchemaPropsOrArray{
+						Schema: &apiextensions.JSONSchemaProps{Type: "string"},
+					},
+				},
+				segment: fieldpath.Segment{
+					Type:  fieldpath.SegmentField,
+					Field: "-1",
+				},
+			},
+			want: want{err: xperrors.Errorf(errFmtArrayAppend, "-1")},
+		},
 		"AcceptFieldNotPresentWithAdditionalProperties": {
 			name: "Should return no error with AdditionalProperties accessing a missing field",
 			args: args{
@@ -1090,6 +1684,161 @@ func TestIsValidInputForTransform(t *testing.T) {
 				err: true,
 			},
 		},
+		"ValidStringTransformFormatStringVerb": {
+			reason: "Format string transform should not return an error formatting a string with %s",
+			args: args{
+				fromType: v1.TransformIOTypeString,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("prefix-%s"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatIntegerVerb": {
+			reason: "Format string transform should not return an error formatting an integer with %d",
+			args: args{
+				fromType: v1.TransformIOTypeInt64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%03d"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatFloatVerb": {
+			reason: "Format string transform should not return an error formatting a float with %.2f",
+			args: args{
+				fromType: v1.TransformIOTypeFloat64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%.2f"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatQuotedVerbInteger": {
+			reason: "Format string transform should not return an error formatting an integer with %q, as a quoted character literal",
+			args: args{
+				fromType: v1.TransformIOTypeInt64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%q"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatBinaryVerbFloat": {
+			reason: "Format string transform should not return an error formatting a float with %b, in binary scientific notation",
+			args: args{
+				fromType: v1.TransformIOTypeFloat64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%b"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatAnyTypeVerb": {
+			reason: "Format string transform should not return an error formatting any type with %v",
+			args: args{
+				fromType: v1.TransformIOTypeBool,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%v"),
+					},
+				},
+			},
+		},
+		"ValidStringTransformFormatEscapedPercent": {
+			reason: "Format string transform should ignore escaped percent signs",
+			args: args{
+				fromType: v1.TransformIOTypeInt64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%d%%"),
+					},
+				},
+			},
+		},
+		"InvalidStringTransformFormatStringVerbInteger": {
+			reason: "Format string transform should return an error formatting an integer with %s",
+			args: args{
+				fromType: v1.TransformIOTypeInt64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("prefix-%s"),
+					},
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"InvalidStringTransformFormatIntegerVerbString": {
+			reason: "Format string transform should return an error formatting a string with %d",
+			args: args{
+				fromType: v1.TransformIOTypeString,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%d"),
+					},
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"InvalidStringTransformFormatFloatVerbInteger": {
+			reason: "Format string transform should return an error formatting an integer with %f",
+			args: args{
+				fromType: v1.TransformIOTypeInt64,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%f"),
+					},
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		"InvalidStringTransformFormatBoolVerbString": {
+			reason: "Format string transform should return an error formatting a string with %t",
+			args: args{
+				fromType: v1.TransformIOTypeString,
+				t: &v1.Transform{
+					Type: v1.TransformTypeString,
+					String: &v1.StringTransform{
+						Type:   v1.StringTransformTypeFormat,
+						Format: ptr.To("%t"),
+					},
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -1101,3 +1850,302 @@ func TestIsValidInputForTransform(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCombinePatchesOptionalVariables(t *testing.T) {
+	type args struct {
+		comp     *v1.Composition
+		gkToCRDs map[kschema.GroupKind]apiextensions.CustomResourceDefinition
+	}
+	type want struct {
+		warns int
+	}
+	tests := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RequiredVariablesOnly": {
+			reason: "Should not warn about a string combine patch using only required variables",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Strategy: v1.CombineStrategyString,
+						Variables: []v1.CombineVariable{
+							{FromFieldPath: "spec.someField"},
+							{FromFieldPath: "metadata.name"},
+						},
+						String: &v1.StringCombine{Format: "%s-%s"},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+			want: want{warns: 0},
+		},
+		"MixedRequiredAndOptionalVariables": {
+			reason: "Should warn about a string combine patch mixing required and optional variables",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Strategy: v1.CombineStrategyString,
+						Variables: []v1.CombineVariable{
+							{FromFieldPath: "spec.someField"},
+							{FromFieldPath: "spec.someNonRequiredField"},
+						},
+						String: &v1.StringCombine{Format: "%s-%s"},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+			want: want{warns: 1},
+		},
+		"OptionalVariablesInPatchSet": {
+			reason: "Should warn about a string combine patch with optional variables referenced through a patch set",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil,
+					withPatchSets(v1.PatchSet{
+						Name: "combine",
+						Patches: []v1.Patch{{
+							Type: v1.PatchTypeCombineFromComposite,
+							Combine: &v1.Combine{
+								Strategy: v1.CombineStrategyString,
+								Variables: []v1.CombineVariable{
+									{FromFieldPath: "spec.someField"},
+									{FromFieldPath: "spec.someNonRequiredField"},
+								},
+								String: &v1.StringCombine{Format: "%s-%s"},
+							},
+							ToFieldPath: ptr.To("spec.someOtherField"),
+						}},
+					}),
+					withPatches(0, v1.Patch{
+						Type:         v1.PatchTypePatchSet,
+						PatchSetName: ptr.To("combine"),
+					})),
+			},
+			want: want{warns: 1},
+		},
+		"OptionalVariablesWithRequiredPolicy": {
+			reason: "Should not warn about a string combine patch with optional variables if the policy requires them",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Strategy: v1.CombineStrategyString,
+						Variables: []v1.CombineVariable{
+							{FromFieldPath: "spec.someField"},
+							{FromFieldPath: "spec.someNonRequiredField"},
+						},
+						String: &v1.StringCombine{Format: "%s-%s"},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+					Policy: &v1.PatchPolicy{
+						FromFieldPath: ptr.To(v1.FromFieldPathPolicyRequired),
+					},
+				})),
+			},
+			want: want{warns: 0},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(WithCRDGetterFromMap(tc.args.gkToCRDs))
+			if err != nil {
+				t.Fatalf("NewValidator(...) = %v", err)
+			}
+			warns, errs := v.Validate(context.TODO(), tc.args.comp)
+			if len(errs) != 0 {
+				t.Fatalf("%s\nValidate(...): unexpected errors: %v", tc.reason, errs)
+			}
+			if diff := cmp.Diff(tc.want.warns, len(warns)); diff != "" {
+				t.Errorf("%s\nValidate(...): -want warnings, +got warnings:\n%s\n%v", tc.reason, diff, warns)
+			}
+		})
+	}
+}
+
+func TestValidatePatchesWithSchemasServedVersionsHint(t *testing.T) {
+	// The Managed CRD serves v1, having spec.newField, and v1beta1, not having it.
+	managedCRD := defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+		crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["newField"] = extv1.JSONSchemaProps{
+			Type: "string",
+		}
+	}).withOption(specSchemaOption("v1beta1", extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"someOtherField": {
+				Type: "string",
+			},
+		},
+	})).build()
+	// The Composite CRD serves v1, referenced by the Composition, and v1beta1,
+	// having spec.legacyField.
+	compositeCRD := defaultCompositeCrdBuilder().withOption(specSchemaOption("v1beta1", extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"someField": {
+				Type: "string",
+			},
+			"legacyField": {
+				Type: "string",
+			},
+		},
+	})).build()
+	withBaseVersion := func(version string) compositionBuilderOption {
+		return func(c *v1.Composition) {
+			base := map[string]any{}
+			if err := json.Unmarshal(c.Spec.Resources[0].Base.Raw, &base); err != nil {
+				t.Fatal(err)
+			}
+			base["apiVersion"] = testGroup + "/" + version
+			c.Spec.Resources[0].Base.Raw = marshalJSON(t, base)
+		}
+	}
+
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		errs field.ErrorList
+	}
+	tests := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FieldInTargetedVersion": {
+			reason: "Should accept a patch to a field existing in the targeted version",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.newField"),
+				})),
+			},
+		},
+		"FieldInOtherServedVersion": {
+			reason: "Should hint that a field not existing in the targeted version exists in another served version",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withBaseVersion("v1beta1"), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.newField"),
+				})),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].patches[0].toFieldPath",
+						BadValue: "spec.newField",
+						Detail:   "field 'newField' is not valid according to the schema, but it's valid for served version(s) v1 of Managed, while version v1beta1 is targeted",
+					},
+				},
+			},
+		},
+		"FieldInNoServedVersion": {
+			reason: "Should not hint about other versions if the field doesn't exist in any of them",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withBaseVersion("v1beta1"), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.doesNotExist"),
+				})),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].patches[0].toFieldPath",
+						BadValue: "spec.doesNotExist",
+						Detail:   "field 'doesNotExist' is not valid according to the schema",
+					},
+				},
+			},
+		},
+		"CompositeFieldInOtherServedVersion": {
+			reason: "Should hint that a composite resource field not existing in the version referenced by compositeTypeRef exists in another served version",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.legacyField"),
+					ToFieldPath:   ptr.To("spec.someOtherField"),
+				})),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].patches[0].fromFieldPath",
+						BadValue: "spec.legacyField",
+						Detail:   "field 'legacyField' is not valid according to the schema, but it's valid for served version(s) v1beta1 of Composite, while version v1 is targeted",
+					},
+				},
+			},
+		},
+		"ToCompositeFieldInOtherServedVersion": {
+			reason: "Should hint that a composite resource field patched to, not existing in the version referenced by compositeTypeRef, exists in another served version",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someOtherField"),
+					ToFieldPath:   ptr.To("spec.legacyField"),
+				})),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].patches[0].toFieldPath",
+						BadValue: "spec.legacyField",
+						Detail:   "field 'legacyField' is not valid according to the schema, but it's valid for served version(s) v1beta1 of Composite, while version v1 is targeted",
+					},
+				},
+			},
+		},
+		"CombineVariableInOtherServedVersion": {
+			reason: "Should hint that a combine variable not existing in the version referenced by compositeTypeRef exists in another served version",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{FromFieldPath: "spec.someField"},
+							{FromFieldPath: "spec.legacyField"},
+						},
+						Strategy: v1.CombineStrategyString,
+						String:   &v1.StringCombine{Format: "%s-%s"},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].patches[0].combine",
+						BadValue: []v1.CombineVariable{{FromFieldPath: "spec.someField"}, {FromFieldPath: "spec.legacyField"}},
+						Detail:   "fromFieldPath: Invalid value: \"spec.legacyField\": field 'legacyField' is not valid according to the schema, but spec.legacyField is valid for served version(s) v1beta1 of Composite, while version v1 is targeted",
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(WithCRDGetterFromMap(buildGkToCRDs(compositeCRD, managedCRD)))
+			if err != nil {
+				t.Fatalf("NewValidator(...) = %v", err)
+			}
+			got := v.validatePatchesWithSchemas(context.TODO(), tc.args.comp)
+			if diff := cmp.Diff(tc.want.errs, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nvalidatePatchesWithSchemas(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
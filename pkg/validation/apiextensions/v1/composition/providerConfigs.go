@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// providerConfigRefField is the spec field through which managed resources
+// reference the ProviderConfig to use.
+const providerConfigRefField = "providerConfigRef"
+
+// validateProviderConfigRefs returns a warning for each composed resource
+// whose schema has a spec.providerConfigRef field without a default, which is
+// neither set by its base nor patched. Such resources usually fail at runtime,
+// as their provider can't know which ProviderConfig to use.
+func (v *Validator) validateProviderConfigRefs(ctx context.Context, comp *v1.Composition) (warns []string) {
+	for i := range comp.Spec.Resources {
+		ct := &comp.Spec.Resources[i]
+		obj, err := GetBaseObject(ct)
+		if err != nil {
+			// Invalid bases are reported by the logical validation.
+			continue
+		}
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		// Errors getting CRDs are already reported by validatePatchesWithSchemas.
+		crd, err := v.crdGetter.Get(ctx, gvk.GroupKind())
+		if err != nil {
+			continue
+		}
+		s := getSchemaForVersion(crd, gvk.Version)
+		if s == nil {
+			continue
+		}
+		ref, ok := s.Properties["spec"].Properties[providerConfigRefField]
+		if !ok || ref.Default != nil {
+			continue
+		}
+		spec, err := getSpec(obj)
+		if err != nil {
+			continue
+		}
+		if _, ok := spec[providerConfigRefField]; ok {
+			continue
+		}
+		if isPatched(getPatchedFieldPaths(comp, ct), "spec."+providerConfigRefField) {
+			continue
+		}
+		warns = append(warns, fmt.Sprintf("%s: composed resource %s neither sets spec.%s in its base nor patches it, its provider won't know which ProviderConfig to use", field.NewPath("spec", "resources").Index(i), gvk.GroupKind(), providerConfigRefField))
+	}
+	return warns
+}
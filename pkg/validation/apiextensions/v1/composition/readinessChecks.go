@@ -72,8 +72,13 @@ func validateReadinessChecks(resource v1.ComposedTemplate, schema *apiextensions
 			// nothing to do, we don't have a type defined for the field
 			continue
 		}
-		if matchType := getReadinessCheckExpectedType(r); matchType != "" && matchType != fieldType {
-			errs = append(errs, field.Invalid(field.NewPath("readinessCheck").Index(j).Child("fieldPath"), r.FieldPath, fmt.Sprintf("expected field path to be of type %s", matchType)))
+		matchType := getReadinessCheckExpectedType(r)
+		if fieldType == xpschema.KnownJSONTypeIntOrString && matchType.IsEquivalent(fieldType) {
+			// both matchInteger and matchString work for int-or-string fields
+			continue
+		}
+		if matchType != "" && matchType != fieldType {
+			errs = append(errs, field.Invalid(field.NewPath("readinessCheck").Index(j).Child("fieldPath"), r.FieldPath, fmt.Sprintf("expected field path to be of type %s for a %s readiness check, but it is of type %s according to the schema", matchType, r.Type, fieldType)))
 			continue
 		}
 	}
@@ -88,6 +88,49 @@ func TestValidateReadinessCheck(t *testing.T) {
 				errs: nil,
 			},
 		},
+		{
+			name: "should reject invalid readiness check - nonEmpty type - invalid field path",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withReadinessChecks(
+					0,
+					v1.ReadinessCheck{
+						Type:      v1.ReadinessCheckTypeNonEmpty,
+						FieldPath: "spec.someWrongField",
+					},
+				)),
+				gkToCRD: defaultGKToCRDs(),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].readinessCheck[0].fieldPath",
+						BadValue: "spec.someWrongField",
+					},
+				},
+			},
+		},
+		{
+			name: "should accept valid readiness check - nonEmpty type - any field type",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withReadinessChecks(
+					0,
+					v1.ReadinessCheck{
+						Type:      v1.ReadinessCheckTypeNonEmpty,
+						FieldPath: "spec.someField",
+					},
+				)),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "integer",
+						}
+					}).build()),
+			},
+			want: want{
+				errs: nil,
+			},
+		},
 		{
 			name: "should accept valid readiness check - matchTrue type",
 			args: args{
@@ -95,10 +138,15 @@ func TestValidateReadinessCheck(t *testing.T) {
 					0,
 					v1.ReadinessCheck{
 						Type:      v1.ReadinessCheckTypeMatchTrue,
-						FieldPath: "spec.someOtherField",
+						FieldPath: "spec.someField",
 					},
 				)),
-				gkToCRD: defaultGKToCRDs(),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "boolean",
+						}
+					}).build()),
 			},
 			want: want{
 				errs: nil,
@@ -111,10 +159,15 @@ func TestValidateReadinessCheck(t *testing.T) {
 					0,
 					v1.ReadinessCheck{
 						Type:      v1.ReadinessCheckTypeMatchFalse,
-						FieldPath: "spec.someOtherField",
+						FieldPath: "spec.someField",
 					},
 				)),
-				gkToCRD: defaultGKToCRDs(),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "boolean",
+						}
+					}).build()),
 			},
 			want: want{
 				errs: nil,
@@ -187,6 +240,88 @@ func TestValidateReadinessCheck(t *testing.T) {
 				errs: nil,
 			},
 		},
+		{
+			name: "should reject invalid readiness check - matchTrue type - type mismatch",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withReadinessChecks(
+					0,
+					v1.ReadinessCheck{
+						Type:      v1.ReadinessCheckTypeMatchTrue,
+						FieldPath: "spec.someField",
+					},
+				)),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "string",
+						}
+					}).build()),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].readinessCheck[0].fieldPath",
+						BadValue: "spec.someField",
+					},
+				},
+			},
+		},
+		{
+			name: "should reject invalid readiness check - matchFalse type - type mismatch",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withReadinessChecks(
+					0,
+					v1.ReadinessCheck{
+						Type:      v1.ReadinessCheckTypeMatchFalse,
+						FieldPath: "spec.someField",
+					},
+				)),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "integer",
+						}
+					}).build()),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].readinessCheck[0].fieldPath",
+						BadValue: "spec.someField",
+					},
+				},
+			},
+		},
+		{
+			name: "should reject invalid readiness check - matchString type - type mismatch",
+			args: args{
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withReadinessChecks(
+					0,
+					v1.ReadinessCheck{
+						Type:        v1.ReadinessCheckTypeMatchString,
+						MatchString: "bob",
+						FieldPath:   "spec.someField",
+					},
+				)),
+				gkToCRD: buildGkToCRDs(
+					defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+						crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["someField"] = extv1.JSONSchemaProps{
+							Type: "boolean",
+						}
+					}).build()),
+			},
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:     field.ErrorTypeInvalid,
+						Field:    "spec.resources[0].readinessCheck[0].fieldPath",
+						BadValue: "spec.someField",
+					},
+				},
+			},
+		},
 		{
 			name: "should reject invalid readiness check - matchInteger type - type mismatch",
 			args: args{
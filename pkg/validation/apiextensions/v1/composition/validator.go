@@ -18,6 +18,7 @@ package composition
 
 import (
 	"context"
+	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -34,6 +35,8 @@ import (
 type Validator struct {
 	logicalValidation func(*v1.Composition) ([]string, field.ErrorList)
 	crdGetter         CRDGetter
+	xrds              []*v1.CompositeResourceDefinition
+	environmentSchema *apiextensions.JSONSchemaProps
 }
 
 // CRDGetter is used to get all CRDs the Validator needs, either one by one or all at once.
@@ -57,6 +60,14 @@ func NewValidator(opts ...ValidatorOption) (*Validator, error) {
 		f(v)
 	}
 
+	if len(v.xrds) > 0 && v.crdGetter != nil {
+		composites, err := getCompositeCRDs(v.xrds)
+		if err != nil {
+			return nil, err
+		}
+		v.crdGetter = compositeCRDGetter{CRDGetter: v.crdGetter, composites: composites}
+	}
+
 	return v, v.isValid()
 }
 
@@ -94,6 +105,16 @@ func (c crdGetterMap) GetAll(_ context.Context) (map[schema.GroupKind]apiextensi
 	return c, nil
 }
 
+// WithEnvironmentSchema returns a ValidatorOption that configures the Validator
+// to validate the environment side of patches against the given schema, e.g.
+// the one of the data of the EnvironmentConfigs merged into the environment.
+// The environment side of patches is not validated if no schema is given.
+func WithEnvironmentSchema(s *apiextensions.JSONSchemaProps) ValidatorOption {
+	return func(v *Validator) {
+		v.environmentSchema = s
+	}
+}
+
 // WithLogicalValidation returns a ValidatorOption that configures the Validator to use the given function to logically
 // validate the Composition.
 func WithLogicalValidation() ValidatorOption {
@@ -121,24 +142,77 @@ func (v *Validator) Validate(ctx context.Context, obj runtime.Object) (warns []s
 		return nil, append(errs, field.NotSupported(field.NewPath("kind"), obj.GetObjectKind().GroupVersionKind().Kind, []string{v1.CompositionGroupVersionKind.Kind}))
 	}
 
-	// Validate the Composition itself
+	// Validate the Composition itself. Logical errors don't stop validation,
+	// we keep validating the parts of the Composition they don't affect so
+	// that they don't mask any other issue.
 	if v.logicalValidation != nil {
-		if warns, errs := v.logicalValidation(comp); len(errs) != 0 {
-			return warns, errs
+		logicalWarns, logicalErrs := v.logicalValidation(comp)
+		warns = append(warns, logicalWarns...)
+		if len(logicalErrs) != 0 {
+			errs = append(errs, logicalErrs...)
+			comp = withoutInvalidParts(comp, logicalErrs)
 		}
 	}
 
 	// Validate patches given the above CRDs, skip if any of the required CRDs is not available
 	for _, f := range []func(context.Context, *v1.Composition) field.ErrorList{
+		v.validateCompositeTypeRef,
 		v.validatePatchesWithSchemas,
 		v.validateReadinessChecksWithSchemas,
 		v.validateConnectionDetailsWithSchemas,
 		v.validateEnvironmentPatchesWithSchemas,
+		v.validatePatchAndTransformInputsWithSchemas,
+		v.validateFunctionInputsWithSchemas,
+		v.validateNestedCompositesWithSchemas,
 		// TODO(phisco): add more phase 2 validation here
 	} {
 		errs = append(errs, f(ctx, comp)...)
 	}
 
+	// Collect warnings about valid, but likely surprising, configurations.
+	for _, f := range []func(context.Context, *v1.Composition) []string{
+		v.validateCombinePatchesOptionalVariables,
+		v.validateProviderConfigRefs,
+		v.validateComposedNamespaces,
+		v.validatePatchDependencyCycles,
+	} {
+		warns = append(warns, f(ctx, comp)...)
+	}
+
 	// TODO(phisco): add more  phase 3 validation here
-	return nil, errs
+	return warns, errs
+}
+
+// withoutInvalidParts returns a copy of the supplied Composition without the
+// parts the supplied logical errors refer to, so that it's safe to validate
+// what's left against the schemas. Invalid patch sets and resources are
+// replaced by empty ones, so that the indexes of the others don't change.
+func withoutInvalidParts(comp *v1.Composition, errs field.ErrorList) *v1.Composition {
+	out := comp.DeepCopy()
+	for _, err := range errs {
+		if isFieldOf(err.Field, field.NewPath("spec", "environment")) {
+			out.Spec.Environment = nil
+			continue
+		}
+		for i := range out.Spec.PatchSets {
+			if isFieldOf(err.Field, field.NewPath("spec", "patchSets").Index(i)) {
+				// Resources referencing an empty patch set have no patches
+				// from it to validate.
+				out.Spec.PatchSets[i] = v1.PatchSet{Name: out.Spec.PatchSets[i].Name}
+			}
+		}
+		for i := range out.Spec.Resources {
+			if isFieldOf(err.Field, field.NewPath("spec", "resources").Index(i)) {
+				out.Spec.Resources[i] = v1.ComposedTemplate{Name: out.Spec.Resources[i].Name}
+			}
+		}
+	}
+	return out
+}
+
+// isFieldOf returns true if the supplied field is the supplied path, or one of
+// its children.
+func isFieldOf(f string, p *field.Path) bool {
+	ps := p.String()
+	return f == ps || strings.HasPrefix(f, ps+".") || strings.HasPrefix(f, ps+"[")
 }
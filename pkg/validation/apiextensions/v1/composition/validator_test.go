@@ -16,13 +16,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 )
 
 func TestValidatorValidate(t *testing.T) {
 	type args struct {
-		comp     *v1.Composition
-		gkToCRDs map[schema.GroupKind]apiextensions.CustomResourceDefinition
+		comp        *v1.Composition
+		gkToCRDs    map[schema.GroupKind]apiextensions.CustomResourceDefinition
+		xrds        []*v1.CompositeResourceDefinition
+		environment *apiextensions.JSONSchemaProps
 	}
 	type want struct {
 		errs field.ErrorList
@@ -90,6 +94,22 @@ func TestValidatorValidate(t *testing.T) {
 				})),
 			},
 		},
+		"AcceptStrictPatchToAnnotationKeyWithDots": {
+			reason: "Should accept a Composition patching to annotations and labels whose keys contain dots and slashes",
+			want:   want{errs: nil},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("metadata.annotations[example.com/foo]"),
+				}, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("metadata.labels[app.kubernetes.io/name]"),
+					ToFieldPath:   ptr.To("metadata.labels[app.kubernetes.io/name]"),
+				})),
+			},
+		},
 		"RejectStrictInvalidFromFieldPath": {
 			reason: "Should reject a Composition with a patch using a field not allowed by the Composite resource, if all CRDs are found",
 			want: want{
@@ -272,6 +292,155 @@ func TestValidatorValidate(t *testing.T) {
 				})),
 			},
 		},
+		"RejectStrictPatchWithCombinePatchObjectVariable": {
+			reason: "Should reject a Composition with a string combine patch having a variable pointing at an object",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].combine",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{
+								FromFieldPath: "spec.someField",
+							},
+							{
+								FromFieldPath: "metadata.labels",
+							},
+						},
+						Strategy: v1.CombineStrategyString,
+						String: &v1.StringCombine{
+							Format: "%s-%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+		},
+		"AcceptCombineToCompositeVariablesFromComposedSchema": {
+			reason: "Should validate the variables of a combine to composite patch against the schema of the composed resource",
+			want:   want{errs: nil},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineToComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{
+								// Only defined by the composed resource.
+								FromFieldPath: "spec.someOtherField",
+							},
+							{
+								FromFieldPath: "spec.someNonRequiredField",
+							},
+						},
+						Strategy: v1.CombineStrategyString,
+						String: &v1.StringCombine{
+							Format: "%s-%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.someNonRequiredField"),
+				})),
+			},
+		},
+		"RejectCombineToCompositeVariablesFromCompositeSchema": {
+			reason: "Should reject a combine to composite patch using variables only defined by the schema of the composite resource",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].combine",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineToComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{
+								// Only defined by the composite resource.
+								FromFieldPath: "spec.someField",
+							},
+						},
+						Strategy: v1.CombineStrategyString,
+						String: &v1.StringCombine{
+							Format: "%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.someNonRequiredField"),
+				})),
+			},
+		},
+		"RejectCombineToCompositeToFieldPathOnComposedSchema": {
+			reason: "Should validate the toFieldPath of a combine to composite patch against the schema of the composite resource",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineToComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{
+								FromFieldPath: "spec.someOtherField",
+							},
+						},
+						Strategy: v1.CombineStrategyString,
+						String: &v1.StringCombine{
+							Format: "%s",
+						},
+					},
+					// Only defined by the composed resource.
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+		},
+		"AcceptStrictConnectionDetailFromFieldPath": {
+			reason: "Should accept a Composition with a connection detail reading a field defined by the schema of the Managed resource",
+			want:   want{errs: nil},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withConnectionDetails(0, v1.ConnectionDetail{
+					Name:          ptr.To("some-key"),
+					Type:          ptr.To(v1.ConnectionDetailTypeFromFieldPath),
+					FromFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+		},
+		"RejectStrictConnectionDetailInvalidFromFieldPath": {
+			reason: "Should reject a Composition with a connection detail reading a field not allowed by the schema of the Managed resource",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].connectionDetails[0].fromFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withConnectionDetails(0, v1.ConnectionDetail{
+					Name:          ptr.To("some-key"),
+					Type:          ptr.To(v1.ConnectionDetailTypeFromFieldPath),
+					FromFieldPath: ptr.To("spec.someWrongField"),
+				})),
+			},
+		},
 		"AcceptEnvironmentConfigPatchUnsupported": {
 			reason: "Should accept Composition using an EnvironmentConfig related PatchType, if all CRDs are found",
 			want: want{
@@ -553,6 +722,138 @@ func TestValidatorValidate(t *testing.T) {
 				})),
 			},
 		},
+		"AcceptToCompositeFieldPathPatch": {
+			reason: "Should accept a ToCompositeFieldPath patch reading an existing composed resource field and writing an existing composite resource field",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someOtherField"),
+					ToFieldPath:   ptr.To("spec.someNonRequiredField"),
+				})),
+			},
+		},
+		"RejectToCompositeFieldPathPatchInvalidToFieldPath": {
+			reason: "Should reject a ToCompositeFieldPath patch writing a field not existing in the composite resource schema",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someOtherField"),
+					ToFieldPath:   ptr.To("spec.doesNotExist"),
+				})),
+			},
+		},
+		"RejectToCompositeFieldPathPatchInvalidFromFieldPath": {
+			reason: "Should reject a ToCompositeFieldPath patch reading a field not existing in the composed resource schema",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].fromFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someNonRequiredField"),
+				})),
+			},
+		},
+		"RejectToCompositeFieldPathPatchToFieldPathOnlyInComposed": {
+			reason: "Should reject a ToCompositeFieldPath patch writing a field only existing in the composed resource schema",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someOtherField"),
+					ToFieldPath:   ptr.To("spec.someOtherField"),
+				})),
+			},
+		},
+		"AcceptToCompositeFieldPathPatchInPatchSet": {
+			reason: "Should accept a ToCompositeFieldPath patch in a patch set reading a field only existing in the composed resource schema",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatchSets(
+					v1.PatchSet{
+						Name: "some-patch-set",
+						Patches: []v1.Patch{{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someOtherField"),
+							ToFieldPath:   ptr.To("spec.someField"),
+						}},
+					},
+				), withPatches(0, v1.Patch{
+					Type:         v1.PatchTypePatchSet,
+					PatchSetName: ptr.To("some-patch-set"),
+				})),
+			},
+		},
+		"RejectMergeOptionsOnScalarField": {
+			reason: "Should reject a patch setting merge options while patching a scalar field",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].policy.mergeOptions",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someOtherField"),
+					Policy: &v1.PatchPolicy{
+						MergeOptions: &xpv1.MergeOptions{KeepMapValues: ptr.To(true)},
+					},
+				})),
+			},
+		},
+		"AcceptMergeOptionsOnObjectField": {
+			reason: "Should accept a patch setting merge options while patching an object field",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("metadata.labels"),
+					ToFieldPath:   ptr.To("metadata.labels"),
+					Policy: &v1.PatchPolicy{
+						MergeOptions: &xpv1.MergeOptions{KeepMapValues: ptr.To(true)},
+					},
+				})),
+			},
+		},
 		"EnvironmentPatchesHandledProperly": {
 			reason: "Should accept a Composition with an Environment patch, if all CRDs are found",
 			want: want{
@@ -639,30 +940,738 @@ func TestValidatorValidate(t *testing.T) {
 				)),
 			},
 		},
-	}
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			v, err := NewValidator(WithCRDGetterFromMap(tc.args.gkToCRDs))
-			if err != nil {
-				t.Errorf("NewValidator(...) = %v", err)
-				return
-			}
-			_, got := v.Validate(context.TODO(), tc.args.comp)
-			if diff := cmp.Diff(tc.want.errs, got, sortFieldErrors(), cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
-				t.Errorf("%s\nValidate(...) = -want, +got\n%s", tc.reason, diff)
-			}
-		})
-	}
-}
-
-// SortFieldErrors sorts the given field.ErrorList by the error message.
-func sortFieldErrors() cmp.Option {
-	return cmpopts.SortSlices(func(e1, e2 *field.Error) bool {
-		return strings.Compare(e1.Error(), e2.Error()) < 0
-	})
-}
-
-const (
+		"AcceptEnvironmentPatchesWithEnvironmentSchema": {
+			reason: "Should accept patches reading and writing environment keys defined by the environment schema",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs:    defaultGKToCRDs(),
+				environment: defaultEnvironmentSchema(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withEnvironmentPatches(
+					v1.EnvironmentPatch{
+						Type:          v1.PatchTypeFromCompositeFieldPath,
+						FromFieldPath: ptr.To("spec.someNonRequiredField"),
+						ToFieldPath:   ptr.To("tier.name"),
+					},
+				), withPatches(0,
+					v1.Patch{
+						Type:          v1.PatchTypeToEnvironmentFieldPath,
+						FromFieldPath: ptr.To("spec.someOtherField"),
+						ToFieldPath:   ptr.To("tier.name"),
+					},
+					v1.Patch{
+						Type:          v1.PatchTypeFromEnvironmentFieldPath,
+						FromFieldPath: ptr.To("tier.name"),
+						ToFieldPath:   ptr.To("spec.someNonRequiredField"),
+					},
+				)),
+			},
+		},
+		"RejectEnvironmentPatchesWritingUndefinedEnvironmentKey": {
+			reason: "Should reject patches writing environment keys not defined by the environment schema",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.environment.patches[0].toFieldPath",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs:    defaultGKToCRDs(),
+				environment: defaultEnvironmentSchema(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withEnvironmentPatches(
+					v1.EnvironmentPatch{
+						Type:          v1.PatchTypeFromCompositeFieldPath,
+						FromFieldPath: ptr.To("spec.someNonRequiredField"),
+						ToFieldPath:   ptr.To("tier.undefined"),
+					},
+				), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeToEnvironmentFieldPath,
+					FromFieldPath: ptr.To("spec.someOtherField"),
+					ToFieldPath:   ptr.To("undefined"),
+				})),
+			},
+		},
+		"RejectEnvironmentPatchReadingUndefinedEnvironmentKey": {
+			reason: "Should reject patches reading environment keys not defined by the environment schema",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].fromFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs:    defaultGKToCRDs(),
+				environment: defaultEnvironmentSchema(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromEnvironmentFieldPath,
+					FromFieldPath: ptr.To("undefined"),
+					ToFieldPath:   ptr.To("spec.someNonRequiredField"),
+				})),
+			},
+		},
+		"ReportSchemaErrorsAlongLogicalErrors": {
+			reason: "Should report schema errors of valid resources even if other resources are logically invalid",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeRequired,
+						Field: "spec.resources[0].patches[0].fromFieldPath",
+					},
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[1].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, func(c *v1.Composition) {
+					valid := c.Spec.Resources[0].DeepCopy()
+					valid.Name = ptr.To("test-2")
+					c.Spec.Resources = append(c.Spec.Resources, *valid)
+				}, withPatches(0, v1.Patch{
+					Type:        v1.PatchTypeFromCompositeFieldPath,
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				}), withPatches(1, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someOtherWrongField"),
+				})),
+			},
+		},
+		"AcceptPatchAndTransformInput": {
+			reason: "Should accept a pipeline Composition whose patch-and-transform input is valid according to the schemas",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someOtherField"),
+				}), withPatchAndTransformPipeline(t)),
+			},
+		},
+		"RejectPatchAndTransformInputInvalidToFieldPath": {
+			reason: "Should reject a pipeline Composition whose patch-and-transform input has a patch using a field not allowed by the schema of the Managed resource",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.pipeline[0].input.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someOtherWrongField"),
+				}), withPatchAndTransformPipeline(t)),
+			},
+		},
+		"IgnoreOtherFunctionInputs": {
+			reason: "Should not try to validate the input of functions other than patch-and-transform",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.someOtherWrongField"),
+				}), withPatchAndTransformPipeline(t), func(c *v1.Composition) {
+					c.Spec.Pipeline[0].Input.Raw = []byte(strings.Replace(string(c.Spec.Pipeline[0].Input.Raw), "pt.fn.crossplane.io", "other.fn.crossplane.io", 1))
+				}),
+			},
+		},
+		"AcceptFunctionInputMatchingSchema": {
+			reason: "Should accept a pipeline Composition whose function input is valid according to the input schema published by the function",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: buildGkToCRDs(defaultCompositeCrdBuilder().build(), defaultManagedCrdBuilder().build(), functionInputCrdBuilder().build()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withFunctionInput(t, map[string]any{"region": "eu-west-1"})),
+			},
+		},
+		"RejectFunctionInputNotMatchingSchema": {
+			reason: "Should reject a pipeline Composition whose function input is malformed according to the input schema published by the function",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeTypeInvalid,
+						Field: "spec.pipeline[0].input.region",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: buildGkToCRDs(defaultCompositeCrdBuilder().build(), defaultManagedCrdBuilder().build(), functionInputCrdBuilder().build()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withFunctionInput(t, map[string]any{"region": 42})),
+			},
+		},
+		"RejectFunctionInputMissingRequiredField": {
+			reason: "Should reject a pipeline Composition whose function input misses a field required by the input schema published by the function",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeRequired,
+						Field: "spec.pipeline[0].input.region",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: buildGkToCRDs(defaultCompositeCrdBuilder().build(), defaultManagedCrdBuilder().build(), functionInputCrdBuilder().build()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withFunctionInput(t, map[string]any{})),
+			},
+		},
+		"AcceptFunctionInputWithoutSchema": {
+			reason: "Should not validate a function input whose schema isn't available",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withFunctionInput(t, map[string]any{"region": 42})),
+			},
+		},
+		"AcceptNestedCompositeRequiredFieldInBase": {
+			reason: "Should accept a Composition composing a nested composite resource whose required spec fields are set by the base",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, map[string]any{"size": "large"})),
+			},
+		},
+		"AcceptNestedCompositeRequiredFieldPatched": {
+			reason: "Should accept a Composition composing a nested composite resource whose required spec fields are patched",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, nil), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.size"),
+				})),
+			},
+		},
+		"AcceptNestedCompositeMachineryField": {
+			reason: "Should accept a patch to a field Crossplane adds to the schema of every composite resource",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, map[string]any{"size": "large"}), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.compositionRef.name"),
+				})),
+			},
+		},
+		"AcceptSparseNestedCompositeRequiredFieldPatchedByPatchSet": {
+			reason: "Should accept a nested composite resource with a sparse base whose required spec fields are patched by a patch set",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withSparseNestedComposite(t), withPatchSets(
+					v1.PatchSet{
+						Name: "size",
+						Patches: []v1.Patch{{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someField"),
+							ToFieldPath:   ptr.To("spec.size"),
+						}},
+					},
+				), withPatches(0, v1.Patch{
+					Type:         v1.PatchTypePatchSet,
+					PatchSetName: ptr.To("size"),
+				})),
+			},
+		},
+		"AcceptSparseNestedCompositeWholeSpecPatched": {
+			reason: "Should accept a nested composite resource with a sparse base whose whole spec is patched",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withSparseNestedComposite(t), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec"),
+				})),
+			},
+		},
+		"RejectSparseNestedCompositeMissingRequiredField": {
+			reason: "Should reject a nested composite resource with a sparse base whose required spec fields are not patched",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeRequired,
+						Field: "spec.resources[0].base.spec.size",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withSparseNestedComposite(t)),
+			},
+		},
+		"RejectNestedCompositeMissingRequiredField": {
+			reason: "Should reject a Composition composing a nested composite resource whose required spec fields are neither set by the base nor patched",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeRequired,
+						Field: "spec.resources[0].base.spec.size",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, nil)),
+			},
+		},
+		"AcceptNestedCompositeRequiredFieldWithDefault": {
+			reason: "Should accept a nested composite resource not setting a required spec field that has a default",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, map[string]any{"size": "large"})),
+			},
+		},
+		"RejectNestedCompositeMissingRequiredFieldFromCRD": {
+			reason: "Should reject a nested composite resource whose required spec fields are neither set nor patched, recognizing its CRD as controlled by an XRD without being configured with any XRD",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeRequired,
+						Field: "spec.resources[0].base.spec.size",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: withNestedCompositeCRD(t, defaultGKToCRDs()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, nil)),
+			},
+		},
+		"AcceptNestedCompositeRequiredFieldInBaseFromCRD": {
+			reason: "Should accept a nested composite resource whose required spec fields are set by the base, recognizing its CRD as controlled by an XRD without being configured with any XRD",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: withNestedCompositeCRD(t, defaultGKToCRDs()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, map[string]any{"size": "large"})),
+			},
+		},
+		"RejectNestedCompositeInvalidToFieldPath": {
+			reason: "Should reject a patch to a field not defined by the XRD of a nested composite resource",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.resources[0].patches[0].toFieldPath",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{nestedCompositeXRD(t)},
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, nil, withNestedComposite(t, map[string]any{"size": "large"}), withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.doesNotExist"),
+				})),
+			},
+		},
+		"RejectCompositeTypeRefClaimKindFromXRD": {
+			reason: "Should reject a Composition referencing the claim kind offered by one of the supplied XRDs",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.compositeTypeRef.kind",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{claimableXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withCompositeTypeRefKind("Claim")),
+			},
+		},
+		"RejectCompositeTypeRefClaimKindFromCRD": {
+			reason: "Should reject a Composition referencing a namespaced kind whose CRD is controlled by an XRD, i.e. a claim",
+			want: want{
+				errs: field.ErrorList{
+					{
+						Type:  field.ErrorTypeInvalid,
+						Field: "spec.compositeTypeRef.kind",
+					},
+				},
+			},
+			args: args{
+				gkToCRDs: buildGkToCRDs(defaultManagedCrdBuilder().build(), claimCrdBuilder().build()),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withCompositeTypeRefKind("Claim")),
+			},
+		},
+		"AcceptCompositeTypeRefCompositeKindOfClaimableXRD": {
+			reason: "Should accept a Composition referencing the composite resource kind of an XRD offering a claim",
+			want: want{
+				errs: nil,
+			},
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				xrds:     []*v1.CompositeResourceDefinition{claimableXRD(t)},
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeLoose, nil, withCompositeTypeRefKind("NestedComposite")),
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(WithCRDGetterFromMap(tc.args.gkToCRDs), WithCompositeResourceDefinitions(tc.args.xrds...), WithEnvironmentSchema(tc.args.environment))
+			if err != nil {
+				t.Errorf("NewValidator(...) = %v", err)
+				return
+			}
+			_, got := v.Validate(context.TODO(), tc.args.comp)
+			if diff := cmp.Diff(tc.want.errs, got, sortFieldErrors(), cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("%s\nValidate(...) = -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidatorValidateWarnings(t *testing.T) {
+	type args struct {
+		comp     *v1.Composition
+		gkToCRDs map[schema.GroupKind]apiextensions.CustomResourceDefinition
+	}
+	type want struct {
+		warns []string
+	}
+	tests := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoWarnings": {
+			reason: "Should return no warnings for a valid Composition",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}),
+			},
+		},
+		"WarnLogicalValidation": {
+			reason: "Should return the warnings of the logical validation of the Composition",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, func(c *v1.Composition) {
+					dup := *c.Spec.Resources[0].DeepCopy()
+					dup.Name = ptr.To("test-2")
+					c.Spec.Resources = append(c.Spec.Resources, dup)
+				}),
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[1].base: composed resource Managed "test" has the same identity as the one rendered by spec.resources[0], it will overwrite it`,
+				},
+			},
+		},
+		"WarnSchemaAwareValidation": {
+			reason: "Should return the warnings of the schema aware validation of the Composition",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type: v1.PatchTypeCombineFromComposite,
+					Combine: &v1.Combine{
+						Variables: []v1.CombineVariable{
+							{
+								FromFieldPath: "spec.someField",
+							},
+							{
+								FromFieldPath: "spec.someNonRequiredField",
+							},
+						},
+						Strategy: v1.CombineStrategyString,
+						String: &v1.StringCombine{
+							Format: "%s-%s",
+						},
+					},
+					ToFieldPath: ptr.To("spec.someOtherField"),
+				})),
+			},
+			want: want{
+				warns: []string{
+					"spec.resources[0].patches[0]: string combine patch uses variables not required by the schema [spec.someNonRequiredField], the patch won't be applied if any of them is unset; consider making them required or setting defaults",
+				},
+			},
+		},
+		"WarnMissingProviderConfigRef": {
+			reason: "Should warn about a composed resource having a providerConfigRef field neither set by its base nor patched",
+			args: args{
+				gkToCRDs: managedWithProviderConfigRefGKToCRDs(),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}),
+			},
+			want: want{
+				warns: []string{
+					"spec.resources[0]: composed resource Managed.resources.test.com neither sets spec.providerConfigRef in its base nor patches it, its provider won't know which ProviderConfig to use",
+				},
+			},
+		},
+		"WarnNamespacedBaseForClusterScopedKind": {
+			reason: "Should warn about a composed resource setting a namespace in its base while being cluster scoped",
+			args: args{
+				gkToCRDs: clusterScopedManagedGKToCRDs(),
+				comp:     buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}),
+			},
+			want: want{
+				warns: []string{
+					`spec.resources[0].base.metadata.namespace: composed resource Managed.resources.test.com is cluster scoped, the namespace "testns" set by its base will be ignored`,
+				},
+			},
+		},
+		"NoWarningNoNamespaceForClusterScopedKind": {
+			reason: "Should not warn about a cluster scoped composed resource not setting a namespace in its base",
+			args: args{
+				gkToCRDs: clusterScopedManagedGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, func(c *v1.Composition) {
+					c.Spec.Resources[0].Base.Raw = marshalJSON(t, map[string]any{
+						"apiVersion": testGroup + "/v1",
+						"kind":       "Managed",
+						"metadata":   map[string]any{"name": "test"},
+						"spec":       map[string]any{"someOtherField": "test"},
+					})
+				}),
+			},
+		},
+		"WarnPatchDependencyCycle": {
+			reason: "Should warn about composed resources patching from composite resource fields patched to by each other",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"},
+					withPatches(0,
+						v1.Patch{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someField"),
+							ToFieldPath:   ptr.To("spec.someOtherField"),
+						},
+						v1.Patch{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someNonRequiredField"),
+						},
+					),
+					withManagedResource(t, "test-2",
+						v1.Patch{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someNonRequiredField"),
+						},
+						v1.Patch{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someOtherField"),
+							ToFieldPath:   ptr.To("spec.someField"),
+						},
+					),
+				),
+			},
+			want: want{
+				warns: []string{
+					"spec.resources: composed resources test, test-2 depend on each other through composite resource fields, their patches may never converge: test patches from spec.someField, patched to by test-2; test-2 patches from spec.someNonRequiredField, patched to by test",
+				},
+			},
+		},
+		"NoWarningAcyclicPatchDependencies": {
+			reason: "Should not warn about composed resources patching from composite resource fields patched to by others, if there is no cycle",
+			args: args{
+				gkToCRDs: defaultGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"},
+					withPatches(0, v1.Patch{
+						Type:          v1.PatchTypeFromCompositeFieldPath,
+						FromFieldPath: ptr.To("spec.someNonRequiredField"),
+					}),
+					withManagedResource(t, "test-2",
+						v1.Patch{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someField"),
+							ToFieldPath:   ptr.To("spec.someOtherField"),
+						},
+						v1.Patch{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: ptr.To("spec.someNonRequiredField"),
+						},
+					),
+				),
+			},
+		},
+		"NoWarningProviderConfigRefInBase": {
+			reason: "Should not warn about a composed resource setting its providerConfigRef in its base",
+			args: args{
+				gkToCRDs: managedWithProviderConfigRefGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{
+					"someOtherField":    "test",
+					"providerConfigRef": map[string]any{"name": "default"},
+				}),
+			},
+		},
+		"NoWarningProviderConfigRefPatched": {
+			reason: "Should not warn about a composed resource patching its providerConfigRef",
+			args: args{
+				gkToCRDs: managedWithProviderConfigRefGKToCRDs(),
+				comp: buildDefaultComposition(t, v1.SchemaAwareCompositionValidationModeStrict, map[string]any{"someOtherField": "test"}, withPatches(0, v1.Patch{
+					Type:          v1.PatchTypeFromCompositeFieldPath,
+					FromFieldPath: ptr.To("spec.someField"),
+					ToFieldPath:   ptr.To("spec.providerConfigRef.name"),
+				})),
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(WithCRDGetterFromMap(tc.args.gkToCRDs))
+			if err != nil {
+				t.Errorf("NewValidator(...) = %v", err)
+				return
+			}
+			got, _ := v.Validate(context.TODO(), tc.args.comp)
+			if diff := cmp.Diff(tc.want.warns, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nValidate(...) = -want warnings, +got warnings\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithoutInvalidParts(t *testing.T) {
+	patch := v1.Patch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPath: ptr.To("spec.someField")}
+	comp := func(mods ...func(c *v1.Composition)) *v1.Composition {
+		c := &v1.Composition{
+			Spec: v1.CompositionSpec{
+				PatchSets: []v1.PatchSet{
+					{Name: "a", Patches: []v1.Patch{patch}},
+					{Name: "b", Patches: []v1.Patch{patch}},
+				},
+				Resources: []v1.ComposedTemplate{
+					{Name: ptr.To("a"), Patches: []v1.Patch{patch}},
+					{Name: ptr.To("b"), Patches: []v1.Patch{patch}},
+				},
+				Environment: &v1.EnvironmentConfiguration{
+					Patches: []v1.EnvironmentPatch{{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPath: ptr.To("spec.someField")}},
+				},
+			},
+		}
+		for _, m := range mods {
+			m(c)
+		}
+		return c
+	}
+	type args struct {
+		comp *v1.Composition
+		errs field.ErrorList
+	}
+	type want struct {
+		comp *v1.Composition
+	}
+	tests := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"InvalidPatchSet": {
+			reason: "Should only empty the patch set the error refers to, keeping its name",
+			args: args{
+				comp: comp(),
+				errs: field.ErrorList{field.Invalid(field.NewPath("spec", "patchSets").Index(1).Child("patches").Index(0).Child("type"), v1.PatchTypePatchSet, "")},
+			},
+			want: want{
+				comp: comp(func(c *v1.Composition) {
+					c.Spec.PatchSets[1] = v1.PatchSet{Name: "b"}
+				}),
+			},
+		},
+		"InvalidResource": {
+			reason: "Should only empty the resource the error refers to, keeping its name",
+			args: args{
+				comp: comp(),
+				errs: field.ErrorList{field.Required(field.NewPath("spec", "resources").Index(0).Child("patches").Index(0).Child("fromFieldPath"), "")},
+			},
+			want: want{
+				comp: comp(func(c *v1.Composition) {
+					c.Spec.Resources[0] = v1.ComposedTemplate{Name: ptr.To("a")}
+				}),
+			},
+		},
+		"InvalidEnvironment": {
+			reason: "Should drop the environment if an error refers to it",
+			args: args{
+				comp: comp(),
+				errs: field.ErrorList{field.Required(field.NewPath("spec", "environment", "patches").Index(0).Child("fromFieldPath"), "")},
+			},
+			want: want{
+				comp: comp(func(c *v1.Composition) {
+					c.Spec.Environment = nil
+				}),
+			},
+		},
+		"OutOfRangeIndex": {
+			reason: "Should not empty a patch set or resource whose index is a prefix of the one the error refers to",
+			args: args{
+				comp: comp(),
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "patchSets").Index(10), nil, ""),
+					field.Invalid(field.NewPath("spec", "resources").Index(10), nil, ""),
+				},
+			},
+			want: want{
+				comp: comp(),
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := withoutInvalidParts(tc.args.comp, tc.args.errs)
+			if diff := cmp.Diff(tc.want.comp, got); diff != "" {
+				t.Errorf("%s\nwithoutInvalidParts(...) = -want, +got\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// SortFieldErrors sorts the given field.ErrorList by the error message.
+func sortFieldErrors() cmp.Option {
+	return cmpopts.SortSlices(func(e1, e2 *field.Error) bool {
+		return strings.Compare(e1.Error(), e2.Error()) < 0
+	})
+}
+
+const (
 	testGroup         = "resources.test.com"
 	testGroupSingular = "resource.test.com"
 )
@@ -730,6 +1739,33 @@ type crdBuilder struct {
 	opts          []builderOption
 }
 
+// managedWithProviderConfigRefGKToCRDs returns the default CRDs, with the
+// managed resource having a spec.providerConfigRef field.
+func managedWithProviderConfigRefGKToCRDs() map[schema.GroupKind]apiextensions.CustomResourceDefinition {
+	return buildGkToCRDs(
+		defaultCompositeCrdBuilder().build(),
+		defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+			crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["providerConfigRef"] = extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"name": {
+						Type: "string",
+					},
+				},
+			}
+		}).build(),
+	)
+}
+
+func clusterScopedManagedGKToCRDs() map[schema.GroupKind]apiextensions.CustomResourceDefinition {
+	return buildGkToCRDs(
+		defaultCompositeCrdBuilder().build(),
+		defaultManagedCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+			crd.Spec.Scope = extv1.ClusterScoped
+		}).build(),
+	)
+}
+
 func newCRDBuilder(kind, version string) *crdBuilder {
 	return &crdBuilder{kind: kind, version: version}
 }
@@ -826,12 +1862,234 @@ func withEnvironmentPatches(patches ...v1.EnvironmentPatch) compositionBuilderOp
 	}
 }
 
+// withManagedResource appends a Managed composed resource with the given name
+// and patches.
+func withManagedResource(t *testing.T, name string, patches ...v1.Patch) compositionBuilderOption {
+	t.Helper()
+	return func(c *v1.Composition) {
+		c.Spec.Resources = append(c.Spec.Resources, v1.ComposedTemplate{
+			Name: ptr.To(name),
+			Base: runtime.RawExtension{Raw: marshalJSON(t, map[string]any{
+				"apiVersion": testGroup + "/v1",
+				"kind":       "Managed",
+				"metadata": map[string]any{
+					"name":      name,
+					"namespace": "testns",
+				},
+				"spec": map[string]any{"someOtherField": "test"},
+			})},
+			Patches: patches,
+		})
+	}
+}
+
 func withPatchSets(patchSets ...v1.PatchSet) compositionBuilderOption {
 	return func(c *v1.Composition) {
 		c.Spec.PatchSets = patchSets
 	}
 }
 
+// withPatchAndTransformPipeline moves the resources and patch sets of the
+// Composition to the input of a function-patch-and-transform pipeline step.
+func withPatchAndTransformPipeline(t *testing.T) compositionBuilderOption {
+	t.Helper()
+	return func(c *v1.Composition) {
+		c.Spec.Mode = ptr.To(v1.CompositionModePipeline)
+		c.Spec.Pipeline = []v1.PipelineStep{{
+			Step:        "patch-and-transform",
+			FunctionRef: v1.FunctionReference{Name: "function-patch-and-transform"},
+			Input: &runtime.RawExtension{Raw: marshalJSON(t, map[string]any{
+				"apiVersion": "pt.fn.crossplane.io/v1beta1",
+				"kind":       "Resources",
+				"patchSets":  c.Spec.PatchSets,
+				"resources":  c.Spec.Resources,
+			})},
+		}}
+		c.Spec.PatchSets = nil
+		c.Spec.Resources = nil
+	}
+}
+
+// defaultEnvironmentSchema returns the schema of an environment having a
+// tier.name string key.
+func defaultEnvironmentSchema() *apiextensions.JSONSchemaProps {
+	return &apiextensions.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensions.JSONSchemaProps{
+			"tier": {
+				Type: "object",
+				Properties: map[string]apiextensions.JSONSchemaProps{
+					"name": {
+						Type: "string",
+					},
+				},
+			},
+		},
+	}
+}
+
+// functionInputCrdBuilder returns a builder for the CRD a function could
+// publish for its input, requiring a string region.
+func functionInputCrdBuilder() *crdBuilder {
+	return newCRDBuilder("Input", "v1beta1").withOption(func(crd *extv1.CustomResourceDefinition) {
+		crd.Spec.Versions[0].Schema = &extv1.CustomResourceValidation{
+			OpenAPIV3Schema: &extv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"region"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"region": {
+						Type: "string",
+					},
+				},
+			},
+		}
+	})
+}
+
+// withFunctionInput replaces the resources of the Composition with a pipeline
+// having a single step, taking an Input with the supplied fields.
+func withFunctionInput(t *testing.T, fields map[string]any) compositionBuilderOption {
+	t.Helper()
+	return func(c *v1.Composition) {
+		in := map[string]any{
+			"apiVersion": testGroup + "/v1beta1",
+			"kind":       "Input",
+		}
+		for k, v := range fields {
+			in[k] = v
+		}
+		c.Spec.Mode = ptr.To(v1.CompositionModePipeline)
+		c.Spec.Pipeline = []v1.PipelineStep{{
+			Step:        "function",
+			FunctionRef: v1.FunctionReference{Name: "function"},
+			Input:       &runtime.RawExtension{Raw: marshalJSON(t, in)},
+		}}
+		c.Spec.Resources = nil
+	}
+}
+
+// nestedCompositeXRD returns an XRD defining a NestedComposite composite
+// resource with a required spec.size field, and a required spec.tier field
+// having a default.
+func nestedCompositeXRD(t *testing.T) *v1.CompositeResourceDefinition {
+	t.Helper()
+	return &v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "nestedcomposites." + testGroup},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group: testGroup,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:     "NestedComposite",
+				ListKind: "NestedCompositeList",
+				Plural:   "nestedcomposites",
+				Singular: "nestedcomposite",
+			},
+			Versions: []v1.CompositeResourceDefinitionVersion{{
+				Name:          "v1",
+				Served:        true,
+				Referenceable: true,
+				Schema: &v1.CompositeResourceValidation{
+					OpenAPIV3Schema: runtime.RawExtension{Raw: marshalJSON(t, map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"spec": map[string]any{
+								"type":     "object",
+								"required": []string{"size", "tier"},
+								"properties": map[string]any{
+									"size": map[string]any{"type": "string"},
+									"tier": map[string]any{"type": "string", "default": "basic"},
+								},
+							},
+						},
+					})},
+				},
+			}},
+		},
+	}
+}
+
+// withNestedCompositeCRD adds the CRD Crossplane creates for the
+// NestedComposite XRD to the supplied ones.
+func withNestedCompositeCRD(t *testing.T, m map[schema.GroupKind]apiextensions.CustomResourceDefinition) map[schema.GroupKind]apiextensions.CustomResourceDefinition {
+	t.Helper()
+	composites, err := getCompositeCRDs([]*v1.CompositeResourceDefinition{nestedCompositeXRD(t)})
+	if err != nil {
+		t.Fatalf("getCompositeCRDs(...): %v", err)
+	}
+	for gk, crd := range composites {
+		m[gk] = crd
+	}
+	return m
+}
+
+// withNestedComposite replaces the base of the first composed template with
+// a NestedComposite composite resource with the given spec.
+func withNestedComposite(t *testing.T, spec map[string]any) compositionBuilderOption {
+	t.Helper()
+	return func(c *v1.Composition) {
+		if spec == nil {
+			spec = map[string]any{}
+		}
+		c.Spec.Resources[0].Base = runtime.RawExtension{Raw: marshalJSON(t, map[string]any{
+			"apiVersion": testGroup + "/v1",
+			"kind":       "NestedComposite",
+			"metadata": map[string]any{
+				"name": "test",
+			},
+			"spec": spec,
+		})}
+	}
+}
+
+// withSparseNestedComposite replaces the base of the first composed template
+// with a NestedComposite composite resource only setting apiVersion and kind,
+// leaving everything else to patches.
+func withSparseNestedComposite(t *testing.T) compositionBuilderOption {
+	t.Helper()
+	return func(c *v1.Composition) {
+		c.Spec.Resources[0].Base = runtime.RawExtension{Raw: marshalJSON(t, map[string]any{
+			"apiVersion": testGroup + "/v1",
+			"kind":       "NestedComposite",
+		})}
+	}
+}
+
+// claimableXRD returns the XRD of the NestedComposite composite resource,
+// offering a Claim claim.
+func claimableXRD(t *testing.T) *v1.CompositeResourceDefinition {
+	t.Helper()
+	xrd := nestedCompositeXRD(t)
+	xrd.Spec.ClaimNames = &extv1.CustomResourceDefinitionNames{
+		Kind:     "Claim",
+		ListKind: "ClaimList",
+		Plural:   "claims",
+		Singular: "claim",
+	}
+	return xrd
+}
+
+// claimCrdBuilder returns a builder for the CRD of the Claim claim, as
+// Crossplane would create it for the XRD offering it.
+func claimCrdBuilder() *crdBuilder {
+	return defaultCompositeCrdBuilder().withOption(func(crd *extv1.CustomResourceDefinition) {
+		crd.Spec.Names.Kind = "Claim"
+		crd.Spec.Scope = extv1.NamespaceScoped
+		crd.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       v1.CompositeResourceDefinitionKind,
+			Name:       "composites." + testGroup,
+			Controller: ptr.To(true),
+		}})
+	})
+}
+
+// withCompositeTypeRefKind sets the kind of the composite resource the
+// Composition is for.
+func withCompositeTypeRefKind(kind string) compositionBuilderOption {
+	return func(c *v1.Composition) {
+		c.Spec.CompositeTypeRef.Kind = kind
+	}
+}
+
 func buildDefaultComposition(t *testing.T, validationMode v1.CompositionValidationMode, spec map[string]any, opts ...compositionBuilderOption) *v1.Composition {
 	t.Helper()
 	if spec == nil {
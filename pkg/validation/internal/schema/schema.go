@@ -32,12 +32,21 @@ const (
 	KnownJSONTypeObject KnownJSONType = "object"
 	// KnownJSONTypeString is the JSON type for strings.
 	KnownJSONTypeString KnownJSONType = "string"
+
+	// KnownJSONTypeIntOrString is not a JSON type, it's used for fields marked
+	// with x-kubernetes-int-or-string, e.g. Kubernetes quantities, which accept
+	// either an integer or a string.
+	KnownJSONTypeIntOrString KnownJSONType = "int-or-string"
 )
 
 // IsEquivalent returns true if the two supplied types are equal, or if the first
 // type is an integer and the second is a number. This is because the JSON
-// schema spec allows integers to be used in place of numbers.
+// schema spec allows integers to be used in place of numbers. Integers and
+// strings are also equivalent to int-or-string.
 func (t KnownJSONType) IsEquivalent(t2 KnownJSONType) bool {
+	if t2 == KnownJSONTypeIntOrString {
+		return t == t2 || t == KnownJSONTypeInteger || t == KnownJSONTypeString
+	}
 	// integer is a subset of number per JSON specification:
 	// https://datatracker.ietf.org/doc/html/draft-zyp-json-schema-04#section-3.5
 	return t == t2 || (t == KnownJSONTypeInteger && t2 == KnownJSONTypeNumber)
@@ -90,6 +99,9 @@ func FromKnownJSONType(t KnownJSONType) (v1.TransformIOType, error) {
 		return v1.TransformIOTypeObject, nil
 	case KnownJSONTypeArray:
 		return v1.TransformIOTypeObject, nil
+	case KnownJSONTypeIntOrString:
+		// We can't know which of the two types the value will have.
+		return "", nil
 	case KnownJSONTypeNull:
 		return "", errors.Errorf(errFmtUnsupportedJSONType, t)
 	default:
@@ -84,6 +84,24 @@ func TestKnownJSONTypeIsEquivalent(t *testing.T) {
 			t2:   KnownJSONTypeInteger,
 			want: false,
 		},
+		{
+			name: "Integers are equivalent to int-or-string",
+			t:    KnownJSONTypeInteger,
+			t2:   KnownJSONTypeIntOrString,
+			want: true,
+		},
+		{
+			name: "Strings are equivalent to int-or-string",
+			t:    KnownJSONTypeString,
+			t2:   KnownJSONTypeIntOrString,
+			want: true,
+		},
+		{
+			name: "Numbers are not equivalent to int-or-string",
+			t:    KnownJSONTypeNumber,
+			t2:   KnownJSONTypeIntOrString,
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {